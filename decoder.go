@@ -0,0 +1,32 @@
+package envconfig
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// registeredDecoders holds the decoders registered by RegisterDecoder
+var registeredDecoders sync.Map // map[reflect.Type]func(string, reflect.Value) error
+
+// RegisterDecoder registers fn to decode values of type t, e.g. a third party type that implements none of
+// Decoder, Setter or the encoding interfaces. fn sets target, a settable value of type t, from value.
+// Registered decoders take precedence over the methods of the type, pointers to t are allocated as needed
+// and structs of type t are not configured field by field. It replaces a previously registered decoder.
+func RegisterDecoder(t reflect.Type, fn func(value string, target reflect.Value) error) {
+	if t == nil || fn == nil {
+		panic(fmt.Sprintf("envconfig: RegisterDecoder: nil type or decoder for %v", t))
+	}
+
+	registeredDecoders.Store(t, fn)
+}
+
+// registeredDecoder returns the decoder registered for t, or nil.
+func registeredDecoder(t reflect.Type) func(string, reflect.Value) error {
+	fn, ok := registeredDecoders.Load(t)
+	if !ok {
+		return nil
+	}
+
+	return fn.(func(string, reflect.Value) error)
+}
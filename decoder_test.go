@@ -0,0 +1,84 @@
+package envconfig
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// coordinate stands for a third party type without any decoding method
+type coordinate struct {
+	Lat, Lng float64
+}
+
+// upperText has a method the registered decoder takes precedence over
+type upperText string
+
+func (u *upperText) UnmarshalText(text []byte) error {
+	*u = upperText(strings.ToUpper(string(text)))
+	return nil
+}
+
+func TestRegisterDecoder(t *testing.T) {
+	RegisterDecoder(reflect.TypeOf(coordinate{}), func(value string, target reflect.Value) error {
+		var c coordinate
+		if _, err := fmt.Sscanf(value, "%f/%f", &c.Lat, &c.Lng); err != nil {
+			return errors.New("expected lat/lng")
+		}
+		target.Set(reflect.ValueOf(c))
+		return nil
+	})
+	RegisterDecoder(reflect.TypeOf(upperText("")), func(value string, target reflect.Value) error {
+		target.SetString("registered " + value)
+		return nil
+	})
+
+	var s struct {
+		Home   coordinate
+		Office *coordinate
+		Stops  []coordinate
+		Label  upperText
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_HOME", "52.5/13.4")
+	os.Setenv("ENV_CONFIG_OFFICE", "48.1/11.6")
+	os.Setenv("ENV_CONFIG_STOPS", "1/2,3/4")
+	os.Setenv("ENV_CONFIG_LABEL", "home")
+
+	if assert.NoError(t, Process(&s, WithPrefix("env_config"))) {
+		assert.Equal(t, coordinate{52.5, 13.4}, s.Home)
+		if assert.NotNil(t, s.Office) {
+			assert.Equal(t, coordinate{48.1, 11.6}, *s.Office)
+		}
+		assert.Equal(t, []coordinate{{1, 2}, {3, 4}}, s.Stops)
+		assert.Equal(t, upperText("registered home"), s.Label)
+	}
+
+	// the struct is a single variable
+	infos, err := Describe(&s, WithPrefix("env_config"))
+	if assert.NoError(t, err) && assert.Len(t, infos, 4) {
+		assert.Equal(t, "ENV_CONFIG_HOME", infos[0].Key)
+	}
+
+	os.Setenv("ENV_CONFIG_HOME", "berlin")
+	err = Process(&s, WithPrefix("env_config"))
+	v, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("expected ParseError, got %T %v", err, err)
+	}
+	assert.Equal(t, "ENV_CONFIG_HOME", v.KeyName)
+	assert.EqualError(t, v.Err, "expected lat/lng")
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, Usagef(&s, buf, "{{range .}}{{usage_type .}}\n{{end}}"))
+	assert.Equal(t, "coordinate\ncoordinate\nComma-separated list of coordinate\nupperText\n", buf.String())
+
+	assert.Panics(t, func() { RegisterDecoder(nil, nil) })
+}
@@ -0,0 +1,44 @@
+package envconfig
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultFuncPrefix marks default tags computed by a function registered with RegisterDefaultFunc,
+// e.g. `default:"$func:hostname"`.
+const DefaultFuncPrefix = "$func:"
+
+// defaultFuncs holds the functions registered by RegisterDefaultFunc
+var defaultFuncs sync.Map // map[string]func() (string, error)
+
+func init() {
+	RegisterDefaultFunc("hostname", os.Hostname)
+	RegisterDefaultFunc("pid", func() (string, error) {
+		return strconv.Itoa(os.Getpid()), nil
+	})
+}
+
+// RegisterDefaultFunc registers a function computing defaults tagged `default:"$func:<name>"`.
+// The functions hostname and pid are registered by default. It replaces a previously registered function.
+func RegisterDefaultFunc(name string, fn func() (string, error)) {
+	defaultFuncs.Store(name, fn)
+}
+
+// callDefaultFunc computes a default tagged with DefaultFuncPrefix, other values are returned as is.
+func callDefaultFunc(value string) (string, error) {
+	name, ok := strings.CutPrefix(value, DefaultFuncPrefix)
+	if !ok {
+		return value, nil
+	}
+
+	fn, ok := defaultFuncs.Load(name)
+	if !ok {
+		return "", fmt.Errorf("unknown default function %q", name)
+	}
+
+	return fn.(func() (string, error))()
+}
@@ -0,0 +1,58 @@
+package envconfig
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultFunc(t *testing.T) {
+	RegisterDefaultFunc("cpus", func() (string, error) {
+		return "8", nil
+	})
+	RegisterDefaultFunc("broken", func() (string, error) {
+		return "", errors.New("not available")
+	})
+
+	var s struct {
+		Host    string `default:"$func:hostname"`
+		PID     int    `default:"$func:pid"`
+		Workers int    `default:"$func:cpus"`
+		Name    string `default:"app"`
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_WORKERS", "2")
+
+	hostname, err := os.Hostname()
+	assert.NoError(t, err)
+
+	if assert.NoError(t, Process(&s, WithPrefix("env_config"), WithValidateDefaults())) {
+		assert.Equal(t, hostname, s.Host)
+		assert.Equal(t, os.Getpid(), s.PID)
+		assert.Equal(t, 2, s.Workers)
+		assert.Equal(t, "app", s.Name)
+	}
+
+	os.Clearenv()
+	if assert.NoError(t, Process(&s, WithPrefix("env_config"))) {
+		assert.Equal(t, 8, s.Workers)
+	}
+
+	var broken struct {
+		Zone string `default:"$func:broken"`
+		Rack string `default:"$func:rack"`
+	}
+	err = Process(&broken, WithPrefix("env_config"))
+	assert.EqualError(t, err, "default of field Zone: not available")
+
+	err = ProcessAll(&broken, WithPrefix("env_config"))
+	assert.EqualError(t, err, "default of field Zone: not available\ndefault of field Rack: unknown default function \"rack\"")
+
+	os.Setenv("ENV_CONFIG_ZONE", "a")
+	os.Setenv("ENV_CONFIG_RACK", strconv.Itoa(1))
+	assert.NoError(t, Process(&broken, WithPrefix("env_config")))
+}
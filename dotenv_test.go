@@ -0,0 +1,121 @@
+package envconfig
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseEnvFile(t *testing.T) {
+	content := `
+# comment
+APP_HOST=localhost
+export APP_PORT = 8080
+APP_EMPTY=
+APP_DOUBLE="multi\nline" # trailing comment
+APP_SINGLE='single # not a comment'
+APP_INLINE=value # comment
+`
+
+	values, err := parseEnvFile(strings.NewReader(content))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"APP_HOST":   "localhost",
+		"APP_PORT":   "8080",
+		"APP_EMPTY":  "",
+		"APP_DOUBLE": "multi\nline",
+		"APP_SINGLE": "single # not a comment",
+		"APP_INLINE": "value",
+	}, values)
+
+	_, err = parseEnvFile(strings.NewReader("APP_HOST=localhost\nAPP_PORT"))
+	assert.EqualError(t, err, "line 2: expected KEY=VALUE")
+
+	_, err = parseEnvFile(strings.NewReader(`APP_HOST="localhost`))
+	assert.EqualError(t, err, `line 1: unterminated quoted value "localhost`)
+}
+
+func TestDefaultsFromEnvFile(t *testing.T) {
+	var s struct {
+		Host    string `default:"tag-host"`
+		Port    int    `default:"80"`
+		Timeout string `default:"tag-timeout"`
+	}
+
+	os.Clearenv()
+	os.Setenv("APP_PORT", "9090")
+
+	defaults := WithDefaultsFromEnvFile(strings.NewReader("APP_HOST=file-host\nAPP_PORT=8080\n"))
+	err := Process(&s, WithPrefix("app"), defaults)
+	assert.NoError(t, err)
+	assert.Equal(t, "file-host", s.Host)
+	assert.Equal(t, 9090, s.Port)
+	assert.Equal(t, "tag-timeout", s.Timeout)
+
+	// the content is read once, the option may be reused
+	buf := new(bytes.Buffer)
+	assert.NoError(t, Usagef(&s, buf, "{{range .}}{{usage_current .}}\n{{end}}", WithPrefix("app"), defaults))
+	assert.Equal(t, "file-host\n9090\ntag-timeout\n", buf.String())
+	s.Host = ""
+	assert.NoError(t, Process(&s, WithPrefix("app"), defaults))
+	assert.Equal(t, "file-host", s.Host)
+
+	err = Process(&s, WithPrefix("app"), WithDefaultsFromEnvFile(strings.NewReader("APP_HOST")))
+	assert.EqualError(t, err, "parsing defaults: line 1: expected KEY=VALUE")
+}
+
+func TestWithEnvFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	content := "APP_HOST=file-host\nAPP_PORT=8080\n# comment\nAPP_NAME=\"file name\"\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var s struct {
+		Host string
+		Port int
+		Name string
+	}
+
+	os.Clearenv()
+	os.Setenv("APP_HOST", "env-host")
+
+	if assert.NoError(t, Process(&s, WithPrefix("app"), WithEnvFile(path))) {
+		assert.Equal(t, "env-host", s.Host)
+		assert.Equal(t, 8080, s.Port)
+		assert.Equal(t, "file name", s.Name)
+	}
+	_, set := os.LookupEnv("APP_PORT")
+	assert.False(t, set)
+
+	if assert.NoError(t, Process(&s, WithPrefix("app"), WithEnvFileOverride(), WithEnvFile(path))) {
+		assert.Equal(t, "file-host", s.Host)
+	}
+
+	err := Process(&s, WithPrefix("app"), WithEnvFile(filepath.Join(t.TempDir(), "missing.env")))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestLoadEnvFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("APP_HOST=file-host\nAPP_PORT=8080\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Clearenv()
+	os.Setenv("APP_HOST", "env-host")
+
+	assert.NoError(t, LoadEnvFile(path))
+	assert.Equal(t, "env-host", os.Getenv("APP_HOST"))
+	assert.Equal(t, "8080", os.Getenv("APP_PORT"))
+
+	invalid := filepath.Join(t.TempDir(), "invalid.env")
+	if err := os.WriteFile(invalid, []byte("APP_HOST\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	assert.EqualError(t, LoadEnvFile(invalid), "parsing "+invalid+": line 1: expected KEY=VALUE")
+}
@@ -1,25 +1,143 @@
 package envconfig
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 )
 
+// redactedValue replaces values of secret fields wherever they would be exposed.
+const redactedValue = "<redacted>"
+
 var (
 	// ErrInvalidSpecification indicates that a specification is of the wrong type.
 	ErrInvalidSpecification = errors.New("specification must be a struct pointer")
+	// ErrInvalidTarget indicates that the target of ParseInto is not a non-nil pointer.
+	ErrInvalidTarget = errors.New("target must be a non-nil pointer")
 )
 
 // A ParseError occurs when an environment variable cannot be converted to
 // the type required by a struct field during assignment.
 type ParseError struct {
-	KeyName   string
-	FieldName string
+	KeyName string
+	// BaseKeyName is KeyName without the prefix set by WithPrefix.
+	BaseKeyName string
+	FieldName   string
+	// FieldPath is the chain of field names from the specification down to the field, embedded structs included.
+	FieldPath []string
 	TypeName  string
 	Value     string
+	Secret    bool
 	Err       error
 }
 
+// Error implements error. The value of a secret field is redacted.
 func (e *ParseError) Error() string {
-	return fmt.Sprintf("envconfig.Process: assigning %[1]s to %[2]s: converting '%[3]s' to type %[4]s. details: %[5]s", e.KeyName, e.FieldName, e.Value, e.TypeName, e.Err)
+	field := e.FieldName
+	if len(e.FieldPath) > 0 {
+		field = strings.Join(e.FieldPath, ".")
+	}
+	value, errText := e.redacted()
+
+	return fmt.Sprintf("envconfig.Process: assigning %[1]s to %[2]s: converting '%[3]s' to type %[4]s. details: %[5]s", e.KeyName, field, value, e.TypeName, errText)
+}
+
+// redacted returns the value and the text of the underlying error,
+// with the value replaced by redactedValue for secret fields.
+func (e *ParseError) redacted() (value, errText string) {
+	value = e.Value
+	if e.Err != nil {
+		errText = e.Err.Error()
+	}
+
+	if e.Secret {
+		value = redactedValue
+		// underlying conversion errors tend to quote the offending input
+		if e.Value != "" {
+			errText = strings.ReplaceAll(errText, e.Value, redactedValue)
+		}
+	}
+
+	return value, errText
+}
+
+// MarshalJSON implements json.Marshaler. The value of a secret field is redacted.
+func (e *ParseError) MarshalJSON() ([]byte, error) {
+	value, errText := e.redacted()
+
+	return json.Marshal(struct {
+		Key     string `json:"key"`
+		BaseKey string `json:"base_key"`
+		Field   string `json:"field"`
+		Type    string `json:"type"`
+		Value   string `json:"value"`
+		Error   string `json:"error"`
+	}{
+		Key:     e.KeyName,
+		BaseKey: e.BaseKeyName,
+		Field:   e.FieldName,
+		Type:    e.TypeName,
+		Value:   value,
+		Error:   errText,
+	})
+}
+
+// A RequiredError occurs when a required environment variable is not set.
+type RequiredError struct {
+	KeyName   string
+	FieldName string
+}
+
+// Error implements error.
+func (e *RequiredError) Error() string {
+	return fmt.Sprintf("required key %s missing value", e.KeyName)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (e *RequiredError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Key   string `json:"key"`
+		Field string `json:"field"`
+		Error string `json:"error"`
+	}{
+		Key:   e.KeyName,
+		Field: e.FieldName,
+		Error: e.Error(),
+	})
+}
+
+// A MultiError holds all the errors of ProcessAll, e.g. each ParseError and each RequiredError.
+type MultiError []error
+
+// Error implements error. The messages of the errors are separated by newlines, as with errors.Join.
+func (e MultiError) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+
+	return strings.Join(messages, "\n")
+}
+
+// Unwrap returns the errors for errors.Is and errors.As.
+func (e MultiError) Unwrap() []error {
+	return e
+}
+
+// MarshalJSON implements json.Marshaler as an array of the errors. Errors that do not implement
+// json.Marshaler themselves are represented by their message.
+func (e MultiError) MarshalJSON() ([]byte, error) {
+	elems := make([]any, len(e))
+	for i, err := range e {
+		if _, ok := err.(json.Marshaler); ok {
+			elems[i] = err
+			continue
+		}
+		elems[i] = struct {
+			Error string `json:"error"`
+		}{Error: err.Error()}
+	}
+
+	return json.Marshal(elems)
 }
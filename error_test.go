@@ -0,0 +1,241 @@
+package envconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseErrorMarshalJSON(t *testing.T) {
+	var s struct {
+		Port     int
+		Password int `secret:"true"`
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_PORT", "eighty")
+
+	err := Process(&s, WithPrefix("env_config"))
+	if !assert.IsType(t, &ParseError{}, err) {
+		return
+	}
+
+	data, jsonErr := json.Marshal(err)
+	assert.NoError(t, jsonErr)
+	assert.JSONEq(t, `{
+		"key": "ENV_CONFIG_PORT",
+		"base_key": "PORT",
+		"field": "Port",
+		"type": "int",
+		"value": "eighty",
+		"error": "strconv.ParseInt: parsing \"eighty\": invalid syntax"
+	}`, string(data))
+}
+
+func TestParseErrorMarshalJSONRedactsSecret(t *testing.T) {
+	var s struct {
+		Password int `secret:"true"`
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_PASSWORD", "hunter2")
+
+	err := Process(&s, WithPrefix("env_config"))
+	v, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("expected ParseError, got %T %v", err, err)
+	}
+	assert.True(t, v.Secret)
+
+	data, jsonErr := json.Marshal(v)
+	assert.NoError(t, jsonErr)
+	assert.NotContains(t, string(data), "hunter2")
+
+	var decoded map[string]string
+	assert.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, "<redacted>", decoded["value"])
+	assert.Equal(t, "ENV_CONFIG_PASSWORD", decoded["key"])
+}
+
+func TestParseErrorBaseKeyName(t *testing.T) {
+	var s struct {
+		DB struct {
+			Port int
+		}
+	}
+
+	os.Clearenv()
+	os.Setenv("APP_DB_PORT", "eighty")
+
+	err := Process(&s, WithPrefix("app"))
+	v, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("expected ParseError, got %T %v", err, err)
+	}
+	assert.Equal(t, "APP_DB_PORT", v.KeyName)
+	assert.Equal(t, "DB_PORT", v.BaseKeyName)
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, Usagef(&s, buf, "{{range .}}{{usage_key .}} {{usage_base_key .}}\n{{end}}", WithPrefix("app")))
+	assert.Equal(t, "APP_DB_PORT DB_PORT\n", buf.String())
+
+	os.Clearenv()
+	os.Setenv("DB_PORT", "eighty")
+	err = Process(&s)
+	if v, ok = err.(*ParseError); assert.True(t, ok) {
+		assert.Equal(t, "DB_PORT", v.KeyName)
+		assert.Equal(t, "DB_PORT", v.BaseKeyName)
+	}
+}
+
+func TestParseErrorFieldPath(t *testing.T) {
+	type Embedded struct {
+		Timeout time.Duration
+	}
+	var s struct {
+		Embedded
+		Port     int
+		Database struct {
+			Primary struct {
+				Port int
+			}
+		}
+	}
+
+	os.Clearenv()
+	os.Setenv("APP_DATABASE_PRIMARY_PORT", "eighty")
+
+	err := Process(&s, WithPrefix("app"))
+	v, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("expected ParseError, got %T %v", err, err)
+	}
+	assert.Equal(t, "Port", v.FieldName)
+	assert.Equal(t, []string{"Database", "Primary", "Port"}, v.FieldPath)
+	assert.Contains(t, v.Error(), "assigning APP_DATABASE_PRIMARY_PORT to Database.Primary.Port:")
+
+	os.Clearenv()
+	os.Setenv("APP_TIMEOUT", "soon")
+	err = Process(&s, WithPrefix("app"))
+	if v, ok = err.(*ParseError); assert.True(t, ok) {
+		assert.Equal(t, []string{"Embedded", "Timeout"}, v.FieldPath)
+	}
+
+	os.Clearenv()
+	os.Setenv("APP_PORT", "eighty")
+	err = Process(&s, WithPrefix("app"))
+	if v, ok = err.(*ParseError); assert.True(t, ok) {
+		assert.Equal(t, []string{"Port"}, v.FieldPath)
+		assert.Contains(t, v.Error(), "assigning APP_PORT to Port:")
+	}
+}
+
+func TestParseErrorRedactsSecret(t *testing.T) {
+	var s struct {
+		Pin  int `secret:"true"`
+		Port int
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_PIN", "12ab")
+
+	err := Process(&s, WithPrefix("env_config"))
+	if assert.Error(t, err) {
+		assert.NotContains(t, err.Error(), "12ab")
+		assert.Contains(t, err.Error(), "converting '<redacted>' to type int")
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_PORT", "eighty")
+	err = Process(&s, WithPrefix("env_config"))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "converting 'eighty' to type int")
+	}
+}
+
+func TestRequiredError(t *testing.T) {
+	var s struct {
+		Host string `required:"true"`
+		Port int    `required:"true"`
+		Mode string `required_if:"Host=remote"`
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_PORT", "eighty")
+
+	err := ProcessAll(&s, WithPrefix("env_config"))
+	assert.EqualError(t, err, "required key ENV_CONFIG_HOST missing value\n"+
+		"envconfig.Process: assigning ENV_CONFIG_PORT to Port: converting 'eighty' to type int. details: strconv.ParseInt: parsing \"eighty\": invalid syntax")
+
+	var requiredErr *RequiredError
+	if assert.ErrorAs(t, err, &requiredErr) {
+		assert.Equal(t, "ENV_CONFIG_HOST", requiredErr.KeyName)
+		assert.Equal(t, "Host", requiredErr.FieldName)
+	}
+	var parseErr *ParseError
+	if assert.ErrorAs(t, err, &parseErr) {
+		assert.Equal(t, "Port", parseErr.FieldName)
+	}
+
+	os.Setenv("ENV_CONFIG_HOST", "remote")
+	os.Setenv("ENV_CONFIG_PORT", "80")
+	err = Process(&s, WithPrefix("env_config"))
+	if assert.ErrorAs(t, err, &requiredErr) {
+		assert.Equal(t, "ENV_CONFIG_MODE", requiredErr.KeyName)
+		assert.Equal(t, "Mode", requiredErr.FieldName)
+	}
+}
+
+func TestProcessAllMarshalJSON(t *testing.T) {
+	var s struct {
+		Host   string `required:"true"`
+		Port   int
+		Secret string `secret:"true" minlen:"10"`
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_PORT", "eighty")
+	os.Setenv("ENV_CONFIG_SECRET", "hunter2")
+
+	err := ProcessAll(&s, WithPrefix("env_config"))
+	var multiErr MultiError
+	if !assert.ErrorAs(t, err, &multiErr) {
+		return
+	}
+	assert.Len(t, multiErr, 3)
+
+	data, jsonErr := json.Marshal(err)
+	assert.NoError(t, jsonErr)
+	assert.JSONEq(t, `[
+		{
+			"key": "ENV_CONFIG_HOST",
+			"field": "Host",
+			"error": "required key ENV_CONFIG_HOST missing value"
+		},
+		{
+			"key": "ENV_CONFIG_PORT",
+			"base_key": "PORT",
+			"field": "Port",
+			"type": "int",
+			"value": "eighty",
+			"error": "strconv.ParseInt: parsing \"eighty\": invalid syntax"
+		},
+		{
+			"key": "ENV_CONFIG_SECRET",
+			"base_key": "SECRET",
+			"field": "Secret",
+			"type": "string",
+			"value": "<redacted>",
+			"error": "at least 10 characters required, got 7"
+		}
+	]`, string(data))
+
+	data, jsonErr = json.Marshal(MultiError{errors.New("plain")})
+	assert.NoError(t, jsonErr)
+	assert.JSONEq(t, `[{"error": "plain"}]`, string(data))
+}
@@ -0,0 +1,90 @@
+package envconfig
+
+import "reflect"
+
+// Origins of a value reported by Explain, along with SourceEnv, SourceFile and SourceLiteral.
+const (
+	SourceDefault = "default"
+	SourceUnset   = "unset"
+)
+
+// Resolution describes the value a variable resolves to and where it comes from.
+type Resolution struct {
+	// Key is the environment variable name of the field.
+	Key string
+	// Source is one of SourceEnv, SourceFile, SourceLiteral, SourceDefault or SourceUnset.
+	Source string
+	// Value is the string the field would be parsed from, redacted for secret fields.
+	Value string
+}
+
+// Result reports how the variables of a specification were populated, see ProcessResult.
+type Result struct {
+	// Fields holds a resolution per variable, in processing order.
+	Fields []Resolution
+}
+
+// Explain resolves the variables of the specification like Process does, without assigning them,
+// and reports the value of each variable along with its source. The spec itself is left untouched.
+func Explain(spec any, optsValues ...Option) ([]Resolution, error) {
+	s := reflect.ValueOf(spec)
+	if s.Kind() != reflect.Ptr || s.Elem().Kind() != reflect.Struct {
+		return nil, ErrInvalidSpecification
+	}
+
+	opts := defaultOptions().apply(optsValues...).forSpec(spec)
+
+	// gather on a zero instance, so no nil pointer of the spec gets allocated
+	vars, err := gatherInfo(reflect.New(s.Elem().Type()).Interface(), opts)
+	if err != nil {
+		return nil, err
+	}
+	vars = filterVars(vars, opts)
+
+	// fields defaulting to other fields are resolved as by Process, from the values converted into the zero instance
+	var dependent []*variable
+
+	for _, v := range vars {
+		if _, ok := v.fieldType.Tag.Lookup(TagDefaultFrom); ok {
+			dependent = append(dependent, v)
+			continue
+		}
+
+		value, isLoaded, err := v.value()
+		if err != nil {
+			return nil, err
+		}
+		if isLoaded {
+			v.resolved = value
+			// conversion errors are left to Process, the field keeps its zero value
+			_ = processField(value, v.field, v)
+		}
+	}
+
+	if err = resolveDefaultsFrom(dependent); err != nil {
+		return nil, err
+	}
+
+	return resolutions(vars), nil
+}
+
+// resolutions reports the resolved value and the origin of each variable, secrets redacted.
+func resolutions(vars []*variable) []Resolution {
+	resolutions := make([]Resolution, 0, len(vars))
+	for _, v := range vars {
+		value := v.resolved
+		if v.origin == SourceUnset {
+			value = ""
+		} else if v.isSecret() {
+			value = redactedValue
+		}
+
+		resolutions = append(resolutions, Resolution{
+			Key:    v.key,
+			Source: v.origin,
+			Value:  value,
+		})
+	}
+
+	return resolutions
+}
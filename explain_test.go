@@ -0,0 +1,92 @@
+package envconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplain(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	assert.NoError(t, os.WriteFile(tokenFile, []byte("s3cr3t\n"), 0o600))
+
+	type Database struct {
+		Host string `default:"localhost"`
+		Port int    `empty_as_unset:"true"`
+	}
+
+	var s struct {
+		Name     string
+		Token    string `secret:"true"`
+		Region   string `sources:"env:REGION,literal:eu"`
+		Alias    string `default_from:"Name" default:"ignored"`
+		Database *Database
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_NAME", " api ")
+	os.Setenv("ENV_CONFIG_TOKEN_FILE", tokenFile)
+	os.Setenv("ENV_CONFIG_DATABASE_PORT", "")
+
+	resolutions, err := Explain(&s, WithPrefix("env_config"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, []Resolution{
+			{Key: "ENV_CONFIG_NAME", Source: SourceEnv, Value: "api"},
+			{Key: "ENV_CONFIG_TOKEN", Source: SourceFile, Value: redactedValue},
+			{Key: "ENV_CONFIG_REGION", Source: SourceLiteral, Value: "eu"},
+			{Key: "ENV_CONFIG_ALIAS", Source: SourceDefault, Value: "api"},
+			{Key: "ENV_CONFIG_DATABASE_HOST", Source: SourceDefault, Value: "localhost"},
+			{Key: "ENV_CONFIG_DATABASE_PORT", Source: SourceUnset, Value: ""},
+		}, resolutions)
+	}
+
+	// the spec is not mutated
+	assert.Equal(t, "", s.Name)
+	assert.Equal(t, "", s.Alias)
+	assert.Nil(t, s.Database)
+
+	os.Setenv("ENV_CONFIG_TOKEN_FILE", filepath.Join(t.TempDir(), "missing"))
+	_, err = Explain(&s, WithPrefix("env_config"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	_, err = Explain(s)
+	assert.ErrorIs(t, err, ErrInvalidSpecification)
+}
+
+func TestProcessResult(t *testing.T) {
+	var s struct {
+		Name     string
+		Token    string `secret:"true"`
+		Region   string `sources:"env:REGION,literal:eu"`
+		Host     string `default:"localhost"`
+		Backup   string `default_from:"Host"`
+		Port     int
+		Password string `secret:"true"`
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_NAME", "api")
+	os.Setenv("ENV_CONFIG_TOKEN_FILE", "testdata/token.txt")
+
+	result, err := ProcessResult(&s, WithPrefix("env_config"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, []Resolution{
+			{Key: "ENV_CONFIG_NAME", Source: SourceEnv, Value: "api"},
+			{Key: "ENV_CONFIG_TOKEN", Source: SourceFile, Value: redactedValue},
+			{Key: "ENV_CONFIG_REGION", Source: SourceLiteral, Value: "eu"},
+			{Key: "ENV_CONFIG_HOST", Source: SourceDefault, Value: "localhost"},
+			{Key: "ENV_CONFIG_BACKUP", Source: SourceDefault, Value: "localhost"},
+			{Key: "ENV_CONFIG_PORT", Source: SourceUnset, Value: ""},
+			{Key: "ENV_CONFIG_PASSWORD", Source: SourceUnset, Value: ""},
+		}, result.Fields)
+		assert.Equal(t, "file", s.Token)
+		assert.Equal(t, "localhost", s.Backup)
+	}
+
+	os.Setenv("ENV_CONFIG_PORT", "eighty")
+	result, err = ProcessResult(&s, WithPrefix("env_config"))
+	assert.Error(t, err)
+	assert.Nil(t, result)
+}
@@ -0,0 +1,48 @@
+package envconfig
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// interfaceFactories holds the factories registered by RegisterInterfaceFactory
+var interfaceFactories sync.Map // map[reflect.Type]func(string) (any, error)
+
+// RegisterInterfaceFactory registers the factory of values of the interface type ifaceType.
+// Fields of that type are populated with the value fn returns for the variable value,
+// e.g. a concrete storage backend chosen by name. It replaces a previously registered factory.
+func RegisterInterfaceFactory(ifaceType reflect.Type, fn func(value string) (any, error)) {
+	if ifaceType.Kind() != reflect.Interface {
+		panic(fmt.Sprintf("envconfig: RegisterInterfaceFactory: %s is not an interface", ifaceType))
+	}
+
+	interfaceFactories.Store(ifaceType, fn)
+}
+
+// interfaceValue populates an interface field with the value made by the registered factory.
+func interfaceValue(value string, field reflect.Value) error {
+	typ := field.Type()
+
+	fn, ok := interfaceFactories.Load(typ)
+	if !ok {
+		return fmt.Errorf("no factory registered for interface %s", typ)
+	}
+
+	made, err := fn.(func(string) (any, error))(value)
+	if err != nil {
+		return err
+	}
+
+	madeValue := reflect.ValueOf(made)
+	if !madeValue.IsValid() {
+		field.Set(reflect.Zero(typ))
+		return nil
+	}
+	if !madeValue.Type().AssignableTo(typ) {
+		return fmt.Errorf("factory of interface %s returned %s", typ, madeValue.Type())
+	}
+	field.Set(madeValue)
+
+	return nil
+}
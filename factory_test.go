@@ -0,0 +1,81 @@
+package envconfig
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type storageBackend interface {
+	Name() string
+}
+
+type localBackend struct{}
+
+func (localBackend) Name() string { return "local" }
+
+type s3Backend struct{}
+
+func (*s3Backend) Name() string { return "s3" }
+
+type unregisteredBackend interface {
+	Close() error
+}
+
+func TestRegisterInterfaceFactory(t *testing.T) {
+	RegisterInterfaceFactory(reflect.TypeOf((*storageBackend)(nil)).Elem(), func(value string) (any, error) {
+		switch value {
+		case "local":
+			return localBackend{}, nil
+		case "s3":
+			return &s3Backend{}, nil
+		case "broken":
+			return 42, nil
+		}
+		return nil, fmt.Errorf("unknown backend %q", value)
+	})
+
+	var s struct {
+		Backend  storageBackend
+		Replicas []storageBackend
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_BACKEND", "s3")
+	os.Setenv("ENV_CONFIG_REPLICAS", "local,s3")
+
+	if assert.NoError(t, Process(&s, WithPrefix("env_config"))) {
+		assert.Equal(t, "s3", s.Backend.Name())
+		if assert.Len(t, s.Replicas, 2) {
+			assert.Equal(t, "local", s.Replicas[0].Name())
+			assert.Equal(t, "s3", s.Replicas[1].Name())
+		}
+	}
+
+	for value, expected := range map[string]string{
+		"gcs":    `unknown backend "gcs"`,
+		"broken": "factory of interface envconfig.storageBackend returned int",
+	} {
+		os.Setenv("ENV_CONFIG_BACKEND", value)
+		err := Process(&s, WithPrefix("env_config"))
+		v, ok := err.(*ParseError)
+		if !ok {
+			t.Fatalf("expected ParseError, got %T %v", err, err)
+		}
+		assert.EqualError(t, v.Err, expected)
+	}
+
+	var unregistered struct {
+		Closer unregisteredBackend
+	}
+	os.Setenv("ENV_CONFIG_CLOSER", "file")
+	err := Process(&unregistered, WithPrefix("env_config"))
+	v, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("expected ParseError, got %T %v", err, err)
+	}
+	assert.EqualError(t, v.Err, "no factory registered for interface envconfig.unregisteredBackend")
+}
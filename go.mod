@@ -1,6 +1,6 @@
 module github.com/ekomobile/envconfig2
 
-go 1.19
+go 1.20
 
 require github.com/stretchr/testify v1.8.0
 
@@ -0,0 +1,23 @@
+package envconfig
+
+import "fmt"
+
+// LintSpec inspects the tags of the spec and returns advisory messages about questionable definitions.
+// Unlike the errors of Process, the findings do not prevent the spec from being processed.
+func LintSpec(spec any, optsValues ...Option) ([]string, error) {
+	opts := defaultOptions().apply(optsValues...).forSpec(spec)
+
+	vars, err := gatherInfo(spec, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []string
+	for _, v := range vars {
+		if v.isRequiredWithDefault() {
+			messages = append(messages, fmt.Sprintf("field %s (%s) is required but has a default, required has no effect", v.fieldType.Name, v.key))
+		}
+	}
+
+	return messages, nil
+}
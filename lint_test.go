@@ -0,0 +1,37 @@
+package envconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLintSpec(t *testing.T) {
+	var conflicting struct {
+		Host     string `required:"true" default:"localhost"`
+		Port     int    `required:"true"`
+		Database struct {
+			Name string `required:"true" default:""`
+		}
+	}
+
+	messages, err := LintSpec(&conflicting, WithPrefix("app"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"field Host (APP_HOST) is required but has a default, required has no effect",
+		"field Name (APP_DATABASE_NAME) is required but has a default, required has no effect",
+	}, messages)
+
+	var clean struct {
+		Host string `default:"localhost"`
+		Port int    `required:"true"`
+		Mode string `required:"false" default:"dev"`
+	}
+
+	messages, err = LintSpec(&clean, WithPrefix("app"))
+	assert.NoError(t, err)
+	assert.Empty(t, messages)
+
+	_, err = LintSpec(clean)
+	assert.ErrorIs(t, err, ErrInvalidSpecification)
+}
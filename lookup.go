@@ -0,0 +1,150 @@
+package envconfig
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Lookuper is a source of environment variables.
+type Lookuper interface {
+	// Lookup retrieves the value of the variable named by the key, see os.LookupEnv.
+	Lookup(key string) (string, bool)
+	// Environ returns the variables in the form "key=value", see os.Environ.
+	Environ() []string
+}
+
+// osLookuper looks variables up in the process environment
+type osLookuper struct{}
+
+func (osLookuper) Lookup(key string) (string, bool) {
+	return os.LookupEnv(key)
+}
+
+func (osLookuper) Environ() []string {
+	return os.Environ()
+}
+
+// MapLookuper is a Lookuper backed by a map of variables.
+type MapLookuper map[string]string
+
+func (m MapLookuper) Lookup(key string) (string, bool) {
+	value, ok := m[key]
+	return value, ok
+}
+
+// Environ returns the variables of the map sorted by key.
+func (m MapLookuper) Environ() []string {
+	env := make([]string, 0, len(m))
+	for key, value := range m {
+		env = append(env, key+"="+value)
+	}
+	sort.Strings(env)
+
+	return env
+}
+
+// lookuperChain looks variables up in each Lookuper in order, the first one having a variable wins
+type lookuperChain []Lookuper
+
+func (c lookuperChain) Lookup(key string) (string, bool) {
+	for _, lookuper := range c {
+		if value, ok := lookuper.Lookup(key); ok {
+			return value, true
+		}
+	}
+
+	return "", false
+}
+
+func (c lookuperChain) Environ() []string {
+	var env []string
+	seen := make(map[string]struct{})
+	for _, lookuper := range c {
+		for _, item := range lookuper.Environ() {
+			key, _, _ := strings.Cut(item, "=")
+			if _, ok := seen[key]; !ok {
+				seen[key] = struct{}{}
+				env = append(env, item)
+			}
+		}
+	}
+
+	return env
+}
+
+// scopedLookuper exposes the variables of lookuper starting with prefix, named without it
+type scopedLookuper struct {
+	prefix   string
+	lookuper Lookuper
+}
+
+func (l scopedLookuper) Lookup(key string) (string, bool) {
+	return l.lookuper.Lookup(l.prefix + key)
+}
+
+func (l scopedLookuper) Environ() []string {
+	var env []string
+	for _, item := range l.lookuper.Environ() {
+		if scoped, ok := strings.CutPrefix(item, l.prefix); ok {
+			env = append(env, scoped)
+		}
+	}
+
+	return env
+}
+
+// providerLookuper adapts a typed value provider, like viper.Get, to a Lookuper
+type providerLookuper struct {
+	provide func(key string) (any, bool)
+	opts    *options
+}
+
+func (p providerLookuper) Lookup(key string) (string, bool) {
+	value, ok := p.provide(key)
+	if !ok || value == nil {
+		return "", false
+	}
+
+	return stringifyValue(reflect.ValueOf(value), p.opts), true
+}
+
+// Environ returns nothing as a value provider can not enumerate its keys.
+func (p providerLookuper) Environ() []string {
+	return nil
+}
+
+// stringifyValue formats a provided value the way processField parses it back:
+// slice elements are joined by the default delimiter and map pairs by the default map separator.
+func stringifyValue(value reflect.Value, opts *options) string {
+	switch value.Kind() {
+	case reflect.String:
+		return value.String()
+	case reflect.Interface, reflect.Ptr:
+		if value.IsNil() {
+			return ""
+		}
+		return stringifyValue(value.Elem(), opts)
+	case reflect.Slice, reflect.Array:
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			return string(value.Bytes())
+		}
+		items := make([]string, value.Len())
+		for i := range items {
+			items[i] = stringifyValue(value.Index(i), opts)
+		}
+		return strings.Join(items, opts.delimiter)
+	case reflect.Map:
+		items := make([]string, 0, value.Len())
+		iter := value.MapRange()
+		for iter.Next() {
+			items = append(items, stringifyValue(iter.Key(), opts)+opts.mapSeparator+stringifyValue(iter.Value(), opts))
+		}
+		sort.Strings(items)
+		return strings.Join(items, opts.delimiter)
+	}
+
+	return fmt.Sprint(value.Interface())
+}
@@ -0,0 +1,162 @@
+package envconfig
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithLookuper(t *testing.T) {
+	var s struct {
+		Host  string `required:"true"`
+		Port  int
+		Token string `sources:"env:APP_TOKEN"`
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_HOST", "from-os")
+
+	lookuper := MapLookuper{
+		"ENV_CONFIG_HOST": "localhost",
+		"ENV_CONFIG_PORT": "8080",
+		"APP_TOKEN":       "secret",
+	}
+
+	if assert.NoError(t, Process(&s, WithPrefix("env_config"), WithLookuper(lookuper))) {
+		assert.Equal(t, "localhost", s.Host)
+		assert.Equal(t, 8080, s.Port)
+		assert.Equal(t, "secret", s.Token)
+	}
+
+	os.Clearenv()
+	err := Process(&s, WithPrefix("env_config"), WithLookuper(MapLookuper{}))
+	assert.EqualError(t, err, "required key ENV_CONFIG_HOST missing value")
+}
+
+func TestWithLookuperCheckDisallowed(t *testing.T) {
+	var s struct {
+		Host string
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_UNKNOWN", "from-os")
+
+	lookuper := MapLookuper{"ENV_CONFIG_HOST": "localhost"}
+	assert.NoError(t, CheckDisallowed(&s, WithPrefix("env_config"), WithLookuper(lookuper)))
+
+	lookuper["ENV_CONFIG_HOTS"] = "localhost"
+	err := CheckDisallowed(&s, WithPrefix("env_config"), WithLookuper(lookuper))
+	assert.EqualError(t, err, "unknown environment variable ENV_CONFIG_HOTS")
+}
+
+func TestWithValueProvider(t *testing.T) {
+	var s struct {
+		Port    int
+		Debug   bool
+		Users   []string
+		Weights map[string]int
+		Timeout time.Duration
+		Host    string `default:"localhost"`
+	}
+
+	values := map[string]any{
+		"ENV_CONFIG_PORT":    8080,
+		"ENV_CONFIG_DEBUG":   true,
+		"ENV_CONFIG_USERS":   []string{"rob", "ken"},
+		"ENV_CONFIG_WEIGHTS": map[string]any{"a": 1, "b": 2},
+		"ENV_CONFIG_TIMEOUT": 3 * time.Minute,
+	}
+	provide := func(key string) (any, bool) {
+		value, ok := values[key]
+		return value, ok
+	}
+
+	os.Clearenv()
+	if assert.NoError(t, Process(&s, WithPrefix("env_config"), WithValueProvider(provide))) {
+		assert.Equal(t, 8080, s.Port)
+		assert.True(t, s.Debug)
+		assert.Equal(t, []string{"rob", "ken"}, s.Users)
+		assert.Equal(t, map[string]int{"a": 1, "b": 2}, s.Weights)
+		assert.Equal(t, 3*time.Minute, s.Timeout)
+		assert.Equal(t, "localhost", s.Host)
+	}
+
+	values["ENV_CONFIG_USERS"] = []any{"rob", "ken", "robert"}
+	if assert.NoError(t, Process(&s, WithPrefix("env_config"), WithValueProvider(provide), WithDefaultDelimiter(";"))) {
+		assert.Equal(t, []string{"rob", "ken", "robert"}, s.Users)
+	}
+}
+
+func TestWithEnvironMap(t *testing.T) {
+	var s struct {
+		Host string
+		Port int
+		User string
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_HOST", "from-os")
+	os.Setenv("ENV_CONFIG_USER", "os-user")
+
+	overrides := map[string]string{
+		"ENV_CONFIG_HOST": "localhost",
+		"ENV_CONFIG_PORT": "8080",
+	}
+
+	if assert.NoError(t, Process(&s, WithPrefix("env_config"), WithEnvironMap(overrides, true))) {
+		assert.Equal(t, "localhost", s.Host)
+		assert.Equal(t, 8080, s.Port)
+		assert.Equal(t, "os-user", s.User)
+	}
+
+	s.User = ""
+	if assert.NoError(t, Process(&s, WithPrefix("env_config"), WithEnvironMap(overrides, false))) {
+		assert.Equal(t, "localhost", s.Host)
+		assert.Equal(t, "", s.User)
+	}
+
+	err := CheckDisallowed(&s, WithPrefix("env_config"), WithEnvironMap(map[string]string{"ENV_CONFIG_HOTS": "x"}, true))
+	assert.EqualError(t, err, "unknown environment variable ENV_CONFIG_HOTS")
+}
+
+func TestWithScopedEnviron(t *testing.T) {
+	type spec struct {
+		Host string
+		Port int `default:"80"`
+	}
+
+	os.Clearenv()
+	os.Setenv("SVC_HOST", "svc")
+	os.Setenv("SVC_ADMIN_HOST", "admin")
+	os.Setenv("SVC_ADMIN_PORT", "8080")
+	os.Setenv("HOST", "global")
+
+	var svc, admin spec
+	if assert.NoError(t, Process(&svc, WithScopedEnviron("svc"))) {
+		assert.Equal(t, spec{Host: "svc", Port: 80}, svc)
+	}
+	if assert.NoError(t, Process(&admin, WithScopedEnviron("svc_admin"))) {
+		assert.Equal(t, spec{Host: "admin", Port: 8080}, admin)
+	}
+
+	// variables are presented without the scope
+	var prefixed spec
+	if assert.NoError(t, Process(&prefixed, WithScopedEnviron("svc"), WithPrefix("admin"))) {
+		assert.Equal(t, spec{Host: "admin", Port: 8080}, prefixed)
+	}
+
+	// the longer scope is visible from the shorter one, but not the other way around
+	assert.EqualError(t, CheckDisallowed(&svc, WithScopedEnviron("svc")), "unknown environment variable ADMIN_HOST")
+	assert.NoError(t, CheckDisallowed(&admin, WithScopedEnviron("svc_admin")))
+	os.Setenv("SVC_ADMIN_DEBUG", "true")
+	assert.EqualError(t, CheckDisallowed(&admin, WithScopedEnviron("svc_admin")), "unknown environment variable DEBUG")
+
+	// composes with other lookupers
+	var mapped spec
+	lookuper := MapLookuper{"T1_HOST": "t1", "T2_HOST": "t2", "HOST": "none"}
+	if assert.NoError(t, Process(&mapped, WithScopedEnviron("t2"), WithLookuper(lookuper))) {
+		assert.Equal(t, "t2", mapped.Host)
+	}
+}
@@ -1,17 +1,93 @@
 package envconfig
 
-import "strings"
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	DefaultFileSuffix     = "_FILE"
+	DefaultDelimiter      = ","
+	DefaultMapSeparator   = ":"
+	DefaultValueDelimiter = "|"
+	DefaultKeySeparator   = "_"
+)
+
+// ProcessOrder defines the order in which fields are processed.
+type ProcessOrder int
 
 const (
-	DefaultFileSuffix = "_FILE"
+	// ByDeclaration processes fields in the order they are declared in the struct.
+	ByDeclaration ProcessOrder = iota
+	// ByKey processes fields sorted by their resolved keys.
+	ByKey
 )
 
 type (
 	options struct {
 		prefix            string
+		rootPrefix        string
+		prefixFallback    bool
 		isLoadFromFile    bool
 		defaultFileSuffix string
 		trimSpaces        bool
+		afterAll          func() error
+		processOrder      ProcessOrder
+		echo              io.Writer
+		unsetSentinels    []string
+		unsafeUnexported  bool
+		strictTags        bool
+		profile           string
+		keyFilter         func(key string) bool
+		emptyMapTokens    []string
+		fileDefaults      map[string]string
+		normalizers       map[reflect.Type]func(reflect.Value) error
+		delimiter         string
+		mapSeparator      string
+		allErrors         bool
+		transformers      map[string]Transformer
+		timeLayout        string
+		deferRequired     bool
+		lookuper          Lookuper
+		validation        bool
+		expandDefaults    bool
+		envFile           map[string]string
+		envFileOverride   bool
+		trueValues        []string
+		falseValues       []string
+		fieldPath         []string
+		tagName           string
+		requiredByDefault bool
+
+		strictUnknown         bool
+		strictUnknownPrefixes []string
+		validateDefaults      bool
+		ctx                   context.Context
+		keySeparator          string
+		fieldTransformers     []func(key, value string) string
+		ignoredFields         []string
+		emptyAsUnset          bool
+		preserveKeyCase       bool
+		requireNonEmptyFiles  bool
+		trimCutset            string
+		fileLoadingDisabled   bool
+		inlineFileValues      bool
+		lazyStructPointers    bool
+		lazyPointers          []reflect.Value
+		prefixFromType        bool
+		typeNameTransform     TypeNameTransform
+		result                *Result
+		splitWords            bool
+		scope                 string
+		errorOnUnsupported    bool
+		csvSlices             bool
+		joinInitials          bool
+		err                   error
 	}
 
 	Option func(o *options)
@@ -23,6 +99,13 @@ func defaultOptions() *options {
 		isLoadFromFile:    true,
 		defaultFileSuffix: DefaultFileSuffix,
 		trimSpaces:        true,
+		emptyMapTokens:    []string{"{}", "none"},
+		delimiter:         DefaultDelimiter,
+		mapSeparator:      DefaultMapSeparator,
+		lookuper:          osLookuper{},
+		validation:        true,
+		tagName:           TagEnvconfig,
+		keySeparator:      DefaultKeySeparator,
 	}
 }
 
@@ -31,31 +114,224 @@ func (o *options) apply(opts ...Option) *options {
 		opt(o)
 	}
 
+	// names are cased once it is known whether their case is preserved
+	o.prefix = o.keyCase(o.prefix)
+	o.rootPrefix = o.keyCase(o.rootPrefix)
+	o.profile = o.keyCase(o.profile)
+	// a new slice, the prefixes may be those passed to WithStrictUnknownVars
+	prefixes := make([]string, 0, len(o.strictUnknownPrefixes))
+	for _, prefix := range o.strictUnknownPrefixes {
+		prefixes = append(prefixes, o.keyCase(prefix))
+	}
+	o.strictUnknownPrefixes = prefixes
+
+	// env files are layered once it is known whether they override the environment
+	if o.envFile != nil {
+		if o.envFileOverride {
+			o.lookuper = lookuperChain{MapLookuper(o.envFile), o.lookuper}
+		} else {
+			o.lookuper = lookuperChain{o.lookuper, MapLookuper(o.envFile)}
+		}
+	}
+
+	// the scope applies to every source of variables
+	if o.scope != "" {
+		o.lookuper = scopedLookuper{prefix: o.keyCase(o.scope) + o.keySeparator, lookuper: o.lookuper}
+	}
+
 	return o
 }
 
 func (o *options) copy() *options {
 	return &options{
 		prefix:            o.prefix,
+		rootPrefix:        o.rootPrefix,
+		prefixFallback:    o.prefixFallback,
 		isLoadFromFile:    o.isLoadFromFile,
 		defaultFileSuffix: o.defaultFileSuffix,
 		trimSpaces:        o.trimSpaces,
+		afterAll:          o.afterAll,
+		processOrder:      o.processOrder,
+		echo:              o.echo,
+		unsetSentinels:    o.unsetSentinels,
+		unsafeUnexported:  o.unsafeUnexported,
+		strictTags:        o.strictTags,
+		profile:           o.profile,
+		keyFilter:         o.keyFilter,
+		emptyMapTokens:    o.emptyMapTokens,
+		fileDefaults:      o.fileDefaults,
+		normalizers:       o.normalizers,
+		delimiter:         o.delimiter,
+		mapSeparator:      o.mapSeparator,
+		allErrors:         o.allErrors,
+		transformers:      o.transformers,
+		timeLayout:        o.timeLayout,
+		deferRequired:     o.deferRequired,
+		lookuper:          o.lookuper,
+		validation:        o.validation,
+		expandDefaults:    o.expandDefaults,
+		envFile:           o.envFile,
+		envFileOverride:   o.envFileOverride,
+		trueValues:        o.trueValues,
+		falseValues:       o.falseValues,
+		fieldPath:         o.fieldPath,
+		tagName:           o.tagName,
+		requiredByDefault: o.requiredByDefault,
+
+		strictUnknown:         o.strictUnknown,
+		strictUnknownPrefixes: o.strictUnknownPrefixes,
+		validateDefaults:      o.validateDefaults,
+		ctx:                   o.ctx,
+		keySeparator:          o.keySeparator,
+		fieldTransformers:     o.fieldTransformers,
+		ignoredFields:         o.ignoredFields,
+		emptyAsUnset:          o.emptyAsUnset,
+		preserveKeyCase:       o.preserveKeyCase,
+		requireNonEmptyFiles:  o.requireNonEmptyFiles,
+		trimCutset:            o.trimCutset,
+		fileLoadingDisabled:   o.fileLoadingDisabled,
+		inlineFileValues:      o.inlineFileValues,
+		lazyStructPointers:    o.lazyStructPointers,
+		lazyPointers:          o.lazyPointers,
+		prefixFromType:        o.prefixFromType,
+		typeNameTransform:     o.typeNameTransform,
+		result:                o.result,
+		splitWords:            o.splitWords,
+		scope:                 o.scope,
+		errorOnUnsupported:    o.errorOnUnsupported,
+		csvSlices:             o.csvSlices,
+		joinInitials:          o.joinInitials,
+		err:                   o.err,
+	}
+}
+
+// keyCase converts a name that is part of environment variable names to upper case,
+// unless WithPreserveKeyCase is set.
+func (o *options) keyCase(name string) string {
+	if o.preserveKeyCase {
+		return name
+	}
+
+	return strings.ToUpper(name)
+}
+
+// parseBool parses a value of a bool field: the words set by WithBoolValues are checked first,
+// then the values accepted by strconv.ParseBool. Tags are always parsed with strconv.ParseBool.
+func (o *options) parseBool(value string) (bool, error) {
+	for _, word := range o.trueValues {
+		if strings.EqualFold(value, word) {
+			return true, nil
+		}
+	}
+	for _, word := range o.falseValues {
+		if strings.EqualFold(value, word) {
+			return false, nil
+		}
 	}
+
+	return strconv.ParseBool(value)
+}
+
+// isIgnoredField tells whether the field of the struct being gathered matches one of the patterns set by WithIgnoredFields.
+func (o *options) isIgnoredField(name string) bool {
+	if len(o.ignoredFields) == 0 {
+		return false
+	}
+
+	fieldPath := strings.Join(append(o.fieldPath[:len(o.fieldPath):len(o.fieldPath)], name), ".")
+	for _, pattern := range o.ignoredFields {
+		if matched, _ := path.Match(pattern, fieldPath); matched {
+			return true
+		}
+	}
+
+	return false
 }
 
 func WithPrefix(prefix string) Option {
 	return func(o *options) {
-		o.prefix = strings.ToUpper(prefix)
+		o.prefix = prefix
+		o.rootPrefix = prefix
+		o.prefixFromType = false
 	}
 }
 
+// TypeNameTransform selects how WithPrefixFromType derives the prefix from the name of the specification type.
+type TypeNameTransform int
+
+const (
+	// TypeNameSplitWords splits the name into words joined by underscores like split_words, AppConfig becomes APP_CONFIG.
+	TypeNameSplitWords TypeNameTransform = 1 << iota
+	// TypeNameTrimConfig strips a Config suffix, AppConfig becomes APP.
+	TypeNameTrimConfig
+)
+
+// WithPrefixFromType sets the prefix to the name of the type of the specification transformed by transform,
+// e.g. TypeNameSplitWords|TypeNameTrimConfig makes it SERVER for ServerConfig, and APPCONFIG without transform.
+// Each specification of ProcessMany gets the prefix of its own type. Specifications of unnamed types get no prefix.
+// Nested structs are prefixed as usual. The last of WithPrefix and WithPrefixFromType applies.
+func WithPrefixFromType(transform TypeNameTransform) Option {
+	return func(o *options) {
+		o.prefixFromType = true
+		o.typeNameTransform = transform
+	}
+}
+
+// forSpec returns the options to read spec with: with WithPrefixFromType, a copy prefixed after the type of spec.
+// It is called before anything reads the prefix, e.g. by ProcessPrefix, and keeps the options untouched
+// so that each specification of ProcessMany gets its own prefix.
+func (o *options) forSpec(spec any) *options {
+	if !o.prefixFromType {
+		return o
+	}
+
+	t := reflect.TypeOf(spec)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil {
+		return o
+	}
+
+	specOpts := o.copy()
+	specOpts.prefix = o.typePrefix(t)
+	specOpts.rootPrefix = specOpts.prefix
+	specOpts.prefixFromType = false
+
+	return specOpts
+}
+
+// typePrefix derives the prefix of the specification type t, see WithPrefixFromType.
+func (o *options) typePrefix(t reflect.Type) string {
+	name := t.Name()
+	if o.typeNameTransform&TypeNameTrimConfig != 0 && name != "Config" {
+		name = strings.TrimSuffix(name, "Config")
+	}
+	if o.typeNameTransform&TypeNameSplitWords != 0 {
+		name = strings.Join(splitWords(name, o.joinInitials), "_")
+	}
+
+	return o.keyCase(name)
+}
+
 // WithoutDefaultLoadingFromFiles disables loading values from files pointed by *_FILE vars.
+// Fields tagged with file still load from files, see WithFileLoadingDisabled.
 func WithoutDefaultLoadingFromFiles() Option {
 	return func(o *options) {
 		o.isLoadFromFile = false
 	}
 }
 
+// WithFileLoadingDisabled forbids reading values from files: *_FILE vars are ignored even on fields tagged with file,
+// and file entries of the sources tag are skipped. It takes precedence over the file tag and WithDefaultFileSuffix.
+func WithFileLoadingDisabled() Option {
+	return func(o *options) {
+		o.fileLoadingDisabled = true
+	}
+}
+
+// WithDefaultFileSuffix replaces the _FILE suffix of the variables pointing to the files to load values from.
+// Fields tagged with a suffix, e.g. file:"_PATH", keep their own, while file:"true" uses the default one.
 func WithDefaultFileSuffix(suffix string) Option {
 	suffix = strings.TrimSpace(suffix)
 	if suffix == "" {
@@ -73,3 +349,425 @@ func WithoutTrimSpaces() Option {
 		o.trimSpaces = false
 	}
 }
+
+// WithAfterAll sets a hook invoked by ProcessMany once all specs are populated.
+// A non-nil error returned by the hook is returned by ProcessMany.
+func WithAfterAll(fn func() error) Option {
+	return func(o *options) {
+		o.afterAll = fn
+	}
+}
+
+// WithPrefixFallback makes variables fall back to their unprefixed names when the prefixed ones are not set.
+// The prefixed name always takes precedence when both are set.
+func WithPrefixFallback() Option {
+	return func(o *options) {
+		o.prefixFallback = true
+	}
+}
+
+// WithProcessOrder sets the order in which fields are processed, ByDeclaration by default.
+// It determines which error is reported first.
+func WithProcessOrder(order ProcessOrder) Option {
+	return func(o *options) {
+		o.processOrder = order
+	}
+}
+
+// WithEchoOnProcess writes the resolved configuration as a table to w after a successful processing.
+// Values of fields tagged as secret are redacted.
+func WithEchoOnProcess(w io.Writer) Option {
+	return func(o *options) {
+		o.echo = w
+	}
+}
+
+// WithUnsetSentinel makes values equal to any of the sentinels (after trimming spaces) be treated as not set,
+// so that default and required rules apply. It helps with platforms unable to actually unset a variable.
+func WithUnsetSentinel(values ...string) Option {
+	return func(o *options) {
+		for _, value := range values {
+			o.unsetSentinels = append(o.unsetSentinels, strings.TrimSpace(value))
+		}
+	}
+}
+
+// WithUnsafeUnexported enables populating unexported fields, which are skipped by default.
+//
+// It relies on package unsafe to bypass the read-only restriction reflect puts on unexported fields,
+// so any invariants maintained by setter methods of the type are not enforced.
+// Only use it for structs you own.
+func WithUnsafeUnexported() Option {
+	return func(o *options) {
+		o.unsafeUnexported = true
+	}
+}
+
+// WithStrictTags enables additional consistency checks of the specification before processing.
+// Processing fails if an environment variable name is accepted by more than one field
+// or if a required field also declares a default.
+func WithStrictTags() Option {
+	return func(o *options) {
+		o.strictTags = true
+	}
+}
+
+// WithDefaultProfile sets the active environment profile (e.g. "prod").
+// A file path from a profile specific variable like DB_PASSWORD_FILE_PROD
+// takes precedence over the generic DB_PASSWORD_FILE.
+func WithDefaultProfile(profile string) Option {
+	return func(o *options) {
+		o.profile = strings.TrimSpace(profile)
+	}
+}
+
+// WithKeyFilter restricts fields to those whose keys are accepted by the filter.
+// Other fields are neither processed nor included in usage.
+func WithKeyFilter(filter func(key string) bool) Option {
+	return func(o *options) {
+		o.keyFilter = filter
+	}
+}
+
+// WithEmptyMapTokens replaces the tokens denoting an empty map, `{}` and `none` by default.
+// A map field set to such a token is cleared rather than parsed, which is handy to override a default.
+// Tokens are matched case-insensitively.
+func WithEmptyMapTokens(tokens ...string) Option {
+	return func(o *options) {
+		o.emptyMapTokens = tokens
+	}
+}
+
+// WithDefaultsFromEnvFile reads default values from a dotenv style content (KEY=VALUE lines).
+// They take precedence over default tags, while values set in the environment or via files still win.
+// A content that cannot be parsed makes processing fail. The content is read once, so the option may be reused.
+func WithDefaultsFromEnvFile(r io.Reader) Option {
+	values, err := parseEnvFile(r)
+
+	return func(o *options) {
+		if err != nil {
+			o.err = fmt.Errorf("parsing defaults: %w", err)
+			return
+		}
+
+		if o.fileDefaults == nil {
+			o.fileDefaults = make(map[string]string, len(values))
+		}
+		for key, value := range values {
+			o.fileDefaults[key] = value
+		}
+	}
+}
+
+// WithNormalizer registers fn to be invoked with every field of type t once its value is assigned,
+// whether it comes from the environment or a default. It allows e.g. lowercasing all fields of an Email type.
+func WithNormalizer(t reflect.Type, fn func(reflect.Value) error) Option {
+	return func(o *options) {
+		if o.normalizers == nil {
+			o.normalizers = make(map[reflect.Type]func(reflect.Value) error)
+		}
+		o.normalizers[t] = fn
+	}
+}
+
+// WithDefaultDelimiter sets the separator of slice elements and map pairs for fields without a delimiter tag.
+// An empty delimiter falls back to DefaultDelimiter.
+func WithDefaultDelimiter(delimiter string) Option {
+	if delimiter == "" {
+		delimiter = DefaultDelimiter
+	}
+
+	return func(o *options) {
+		o.delimiter = delimiter
+	}
+}
+
+// WithMapSeparator sets the separator between keys and values of map pairs for fields without a map_separator tag.
+// Pairs are split on the first occurrence of the separator only, so values may contain it.
+// An empty separator falls back to DefaultMapSeparator.
+func WithMapSeparator(separator string) Option {
+	if separator == "" {
+		separator = DefaultMapSeparator
+	}
+
+	return func(o *options) {
+		o.mapSeparator = separator
+	}
+}
+
+// WithTransformers registers named transformers usable in pipe tags.
+// Registered transformers take precedence over the built-in ones with the same name.
+func WithTransformers(transformers map[string]Transformer) Option {
+	return func(o *options) {
+		if o.transformers == nil {
+			o.transformers = make(map[string]Transformer, len(transformers))
+		}
+		for name, fn := range transformers {
+			o.transformers[name] = fn
+		}
+	}
+}
+
+// WithTimeLayout sets the layout (see time.Parse) of time.Time fields without a time_format tag.
+// By default time.Time fields are parsed as RFC 3339.
+func WithTimeLayout(layout string) Option {
+	return func(o *options) {
+		o.timeLayout = layout
+	}
+}
+
+// WithDeferredRequiredChecks makes missing required keys be reported after parse errors of the other fields.
+// Process then fails on a missing required key only if every value converts,
+// and ProcessAll lists all parse errors before the missing required keys.
+// By default errors are reported in the order of the fields.
+func WithDeferredRequiredChecks() Option {
+	return func(o *options) {
+		o.deferRequired = true
+	}
+}
+
+// WithLookuper sets the source of environment variables, the process environment by default.
+func WithLookuper(lookuper Lookuper) Option {
+	return func(o *options) {
+		o.lookuper = lookuper
+	}
+}
+
+// WithValueProvider sets a typed value provider, like viper.Get, as the source of environment variables.
+// Provided values are converted to strings before parsing: slices are joined by the default delimiter
+// and maps by the default delimiter and map separator. CheckDisallowed sees no variables from a provider.
+func WithValueProvider(provide func(key string) (any, bool)) Option {
+	return func(o *options) {
+		o.lookuper = providerLookuper{provide: provide, opts: o}
+	}
+}
+
+// WithoutValidation disables invoking Validate on specs and nested structs implementing Validatable.
+func WithoutValidation() Option {
+	return func(o *options) {
+		o.validation = false
+	}
+}
+
+// WithDefaultExpansion enables expanding ${VAR} and $VAR references in default values (see os.Expand).
+// References are looked up like the variables themselves, values read from the environment are never expanded.
+func WithDefaultExpansion() Option {
+	return func(o *options) {
+		o.expandDefaults = true
+	}
+}
+
+// WithEnvironMap makes variables be looked up in env first. With fallback, variables absent from env
+// are looked up in the source configured so far, the process environment by default; otherwise env is the only source.
+func WithEnvironMap(env map[string]string, fallback bool) Option {
+	return func(o *options) {
+		if fallback {
+			o.lookuper = lookuperChain{MapLookuper(env), o.lookuper}
+		} else {
+			o.lookuper = MapLookuper(env)
+		}
+	}
+}
+
+// WithEnvFile makes Process use the variables of a dotenv file (see LoadEnvFile) without changing the process environment.
+// Variables set in the environment take precedence over the file unless WithEnvFileOverride is given.
+// Variables of files given later take precedence over the ones of files given earlier.
+func WithEnvFile(path string) Option {
+	return func(o *options) {
+		values, err := readEnvFile(path)
+		if err != nil {
+			o.err = err
+			return
+		}
+
+		if o.envFile == nil {
+			o.envFile = make(map[string]string, len(values))
+		}
+		for key, value := range values {
+			o.envFile[key] = value
+		}
+	}
+}
+
+// WithEnvFileOverride makes variables of the files given by WithEnvFile take precedence over the environment.
+func WithEnvFileOverride() Option {
+	return func(o *options) {
+		o.envFileOverride = true
+	}
+}
+
+// WithBoolValues sets additional words accepted by bool fields, e.g. yes/no or on/off, compared case-insensitively.
+// Values matching neither set are parsed with strconv.ParseBool.
+func WithBoolValues(trueValues, falseValues []string) Option {
+	return func(o *options) {
+		o.trueValues = trueValues
+		o.falseValues = falseValues
+	}
+}
+
+// WithTagName sets the name of the tag holding alternate variable names, TagEnvconfig by default.
+// Other tags like default, required or desc are not affected.
+func WithTagName(name string) Option {
+	if name == "" {
+		name = TagEnvconfig
+	}
+
+	return func(o *options) {
+		o.tagName = name
+	}
+}
+
+// WithRequiredByDefault makes fields required unless they have a default tag or are tagged optional:"true".
+// An explicit required tag still takes precedence.
+func WithRequiredByDefault() Option {
+	return func(o *options) {
+		o.requiredByDefault = true
+	}
+}
+
+// WithStrictUnknownVars makes Process fail on environment variables that are not read by any field,
+// like CheckDisallowed does. Only variables starting with one of the prefixes are checked,
+// the prefix set by WithPrefix is used if none are given. Without any prefix the check is skipped.
+func WithStrictUnknownVars(prefixes ...string) Option {
+	return func(o *options) {
+		o.strictUnknown = true
+		o.strictUnknownPrefixes = prefixes
+	}
+}
+
+// WithValidateDefaults makes Process check that every default tag converts to the type of its field
+// before any field is populated, failing with all the invalid defaults at once.
+func WithValidateDefaults() Option {
+	return func(o *options) {
+		o.validateDefaults = true
+	}
+}
+
+// WithKeySeparator sets the separator between the prefix and the name of a variable and between nesting levels,
+// e.g. "__" makes DB_HOST of a nested struct DB__HOST. Words split by split_words are still joined by underscores.
+// An empty separator falls back to DefaultKeySeparator.
+func WithKeySeparator(separator string) Option {
+	if separator == "" {
+		separator = DefaultKeySeparator
+	}
+
+	return func(o *options) {
+		o.keySeparator = separator
+	}
+}
+
+// WithFieldTransformer adds a function transforming every loaded value before it is converted, given the key of the variable.
+// It runs after spaces are trimmed (see WithoutTrimSpaces) and before the pipe tag.
+// Transformers added by several options run in order.
+func WithFieldTransformer(fn func(key, value string) string) Option {
+	return func(o *options) {
+		o.fieldTransformers = append(o.fieldTransformers[:len(o.fieldTransformers):len(o.fieldTransformers)], fn)
+	}
+}
+
+// WithIgnoredFields skips the fields matching any of the patterns as if they were tagged ignored,
+// e.g. to skip fields of third party structs. Patterns are matched against the dot separated path of the field
+// from the specification, like Database.Password, with path.Match: * matches any sequence of characters,
+// so Vendor.* skips every field of the Vendor struct.
+func WithIgnoredFields(patterns ...string) Option {
+	return func(o *options) {
+		o.ignoredFields = append(o.ignoredFields[:len(o.ignoredFields):len(o.ignoredFields)], patterns...)
+	}
+}
+
+// WithEmptyAsUnset treats variables set to an empty value, after spaces are trimmed, as if they were not set,
+// so the default applies and required fields fail. The empty_as_unset tag overrides the option per field.
+func WithEmptyAsUnset() Option {
+	return func(o *options) {
+		o.emptyAsUnset = true
+	}
+}
+
+// WithPreserveKeyCase keeps the case of field names, tags, prefixes and the profile in the names of environment variables,
+// which are converted to upper case by default. Names of the variables pointing to files follow the same case.
+func WithPreserveKeyCase() Option {
+	return func(o *options) {
+		o.preserveKeyCase = true
+	}
+}
+
+// WithRequireNonEmptyFiles makes Process fail when a required field is loaded from a file,
+// pointed by a *_FILE variable, that is empty or only holds spaces, instead of assigning an empty value.
+func WithRequireNonEmptyFiles() Option {
+	return func(o *options) {
+		o.requireNonEmptyFiles = true
+	}
+}
+
+// WithTrimCutset trims the characters of cutset around values (see strings.Trim), e.g. `"'` strips surrounding quotes.
+// It applies after spaces are trimmed, so spaces outside quotes are removed first unless WithoutTrimSpaces is set.
+// Like spaces trimming it does not apply to defaults.
+func WithTrimCutset(cutset string) Option {
+	return func(o *options) {
+		o.trimCutset = cutset
+	}
+}
+
+// WithInlineFileValues accepts the content of a file in place of its path in *_FILE vars, as injected by some orchestrators:
+// a value that is not the path of an existing file is the value itself. Files that exist but cannot be read still fail.
+// The file_inline tag overrides the option per field.
+func WithInlineFileValues() Option {
+	return func(o *options) {
+		o.inlineFileValues = true
+	}
+}
+
+// WithLazyStructPointers leaves nil pointers to nested structs nil unless at least one of the fields of the struct
+// is set in the environment or a file; defaults do not count. The fields of such structs are then neither assigned
+// nor checked for required values. By default a zero instance is allocated for each nil pointer.
+func WithLazyStructPointers() Option {
+	return func(o *options) {
+		o.lazyStructPointers = true
+	}
+}
+
+// WithSplitWords splits the names of all fields into words joined by underscores as if tagged split_words,
+// e.g. LogLevel is read from LOG_LEVEL. Fields tagged split_words:"false" opt out. Keys set by tags are not affected.
+func WithSplitWords() Option {
+	return func(o *options) {
+		o.splitWords = true
+	}
+}
+
+// WithJoinedInitials keeps a single upper case letter with the word that follows when names are split into words,
+// e.g. OAuth2Token is read from OAUTH2_TOKEN instead of O_AUTH2_TOKEN. XPos then becomes XPOS rather than X_POS.
+func WithJoinedInitials() Option {
+	return func(o *options) {
+		o.joinInitials = true
+	}
+}
+
+// WithScopedEnviron restricts the variables to those starting with prefix followed by the key separator,
+// and presents them without it, e.g. to isolate the services of a multi-tenant process: with the scope "tenant1",
+// DB_HOST is read from TENANT1_DB_HOST and CheckDisallowed only sees TENANT1_* variables.
+// It applies to the lookuper and env files set by other options, and to WithPrefix, so the prefix "db"
+// makes DB_HOST read from TENANT1_DB_HOST as well. A scope does not hide the variables of a longer one:
+// the scope "svc" sees SVC_B_HOST of the scope "svc_b" as B_HOST.
+func WithScopedEnviron(prefix string) Option {
+	return func(o *options) {
+		o.scope = strings.TrimSpace(prefix)
+	}
+}
+
+// WithErrorOnUnsupportedType makes Process fail on fields of types it cannot parse, like channels, functions
+// or structs without decoding methods in slices, naming the field and its type, instead of leaving them zero.
+// The check does not depend on whether the variables are set.
+func WithErrorOnUnsupportedType() Option {
+	return func(o *options) {
+		o.errorOnUnsupported = true
+	}
+}
+
+// WithCSVSlices reads the values of slices and arrays as a CSV record separated by their delimiter, so elements
+// may be double quoted to contain it: "Doe, John",Jane is split into two names. Quotes inside quoted elements are
+// doubled. The delimiter must be a single character. Without this option values are split on every delimiter.
+func WithCSVSlices() Option {
+	return func(o *options) {
+		o.csvSlices = true
+	}
+}
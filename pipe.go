@@ -0,0 +1,71 @@
+package envconfig
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Transformer transforms a resolved value before it is converted to the field type.
+type Transformer func(value string) (string, error)
+
+// builtinTransformers are the transformers available to the pipe tag without registration.
+var builtinTransformers = map[string]Transformer{
+	"trim": func(value string) (string, error) {
+		return strings.TrimSpace(value), nil
+	},
+	"lower": func(value string) (string, error) {
+		return strings.ToLower(value), nil
+	},
+	"upper": func(value string) (string, error) {
+		return strings.ToUpper(value), nil
+	},
+	"base64decode": func(value string) (string, error) {
+		decoded, err := base64.StdEncoding.DecodeString(value)
+		return string(decoded), err
+	},
+	"hexdecode": func(value string) (string, error) {
+		decoded, err := hex.DecodeString(value)
+		return string(decoded), err
+	},
+}
+
+// parsePipe resolves a pipe tag: a `|` separated list of transformer names.
+// Transformers registered with WithTransformers take precedence over the built-in ones.
+func parsePipe(tag string, opts *options) ([]namedTransformer, error) {
+	var pipe []namedTransformer
+
+	for _, name := range strings.Split(tag, "|") {
+		name = strings.TrimSpace(name)
+		fn, ok := opts.transformers[name]
+		if !ok {
+			fn, ok = builtinTransformers[name]
+		}
+		if !ok {
+			return nil, fmt.Errorf("unknown transformer %q", name)
+		}
+
+		pipe = append(pipe, namedTransformer{name: name, fn: fn})
+	}
+
+	return pipe, nil
+}
+
+// namedTransformer is a single step of the pipe tag
+type namedTransformer struct {
+	name string
+	fn   Transformer
+}
+
+// applyPipe runs the value through the transformers of the variable in order.
+func (v *variable) applyPipe(value string) (string, error) {
+	for _, step := range v.pipe {
+		var err error
+		if value, err = step.fn(value); err != nil {
+			return "", fmt.Errorf("pipe %s of %s: %w", step.name, v.key, err)
+		}
+	}
+
+	return value, nil
+}
@@ -0,0 +1,76 @@
+package envconfig
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPipe(t *testing.T) {
+	var s struct {
+		Token   string `pipe:"trim|lower|base64decode"`
+		Name    string `pipe:"upper" default:"guest"`
+		Reverse string `pipe:"trim|reverse"`
+	}
+
+	os.Clearenv()
+	// "enp6" is "zzz" encoded, uppercased and padded with spaces
+	os.Setenv("ENV_CONFIG_TOKEN", "  ENP6 ")
+	os.Setenv("ENV_CONFIG_REVERSE", " abc ")
+
+	reverse := func(value string) (string, error) {
+		runes := []rune(value)
+		for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+			runes[i], runes[j] = runes[j], runes[i]
+		}
+		return string(runes), nil
+	}
+
+	err := Process(&s, WithPrefix("env_config"), WithoutTrimSpaces(), WithTransformers(map[string]Transformer{"reverse": reverse}))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "zzz", s.Token)
+		assert.Equal(t, "GUEST", s.Name)
+		assert.Equal(t, "cba", s.Reverse)
+	}
+}
+
+func TestPipeErrors(t *testing.T) {
+	var unknown struct {
+		Token string `pipe:"trim|rot13"`
+	}
+
+	os.Clearenv()
+	err := Process(&unknown, WithPrefix("env_config"))
+	assert.EqualError(t, err, `field Token: unknown transformer "rot13"`)
+
+	var invalid struct {
+		Token string `pipe:"hexdecode"`
+	}
+
+	os.Setenv("ENV_CONFIG_TOKEN", "zz")
+	err = Process(&invalid, WithPrefix("env_config"))
+	assert.ErrorContains(t, err, "pipe hexdecode of ENV_CONFIG_TOKEN")
+}
+
+func TestPipeDefaultFrom(t *testing.T) {
+	var s struct {
+		Greeting string `default:"HI"`
+		Reply    string `pipe:"lower" default_from:"Greeting"`
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_REPLY", "HELLO")
+
+	if assert.NoError(t, Process(&s, WithPrefix("env_config"))) {
+		assert.Equal(t, "HI", s.Greeting)
+		assert.Equal(t, "hello", s.Reply)
+	}
+
+	// the referenced value is already piped, if at all, by its own field
+	os.Unsetenv("ENV_CONFIG_REPLY")
+	s.Reply = ""
+	if assert.NoError(t, Process(&s, WithPrefix("env_config"))) {
+		assert.Equal(t, "HI", s.Reply)
+	}
+}
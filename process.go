@@ -1,13 +1,22 @@
 package envconfig
 
 import (
+	"context"
 	"encoding"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
-	"os"
+	"math"
+	"math/big"
+	"net"
+	"net/url"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
 )
 
 // Decoder has the same semantics as Setter, but takes higher precedence.
@@ -26,7 +35,7 @@ type Setter interface {
 // that we don't know how or expected to parse. This is likely only meaningful with
 // a non-empty prefix.
 func CheckDisallowed(spec any, optsValues ...Option) error {
-	opts := defaultOptions().apply(optsValues...)
+	opts := defaultOptions().apply(optsValues...).forSpec(spec)
 
 	infos, err := gatherInfo(spec, opts)
 	if err != nil {
@@ -39,57 +48,372 @@ func CheckDisallowed(spec any, optsValues ...Option) error {
 	}
 
 	if opts.prefix != "" {
-		opts.prefix = strings.ToUpper(opts.prefix) + "_"
+		opts.prefix = opts.prefix + opts.keySeparator
 	}
 
-	for _, env := range os.Environ() {
-		if !strings.HasPrefix(env, opts.prefix) {
-			continue
-		}
+	return checkUnknownVars(opts.lookuper.Environ(), []string{opts.prefix}, vars)
+}
+
+// checkUnknownVars returns an error for the first variable of environ starting with one of the prefixes that is not known.
+func checkUnknownVars(environ []string, prefixes []string, known map[string]struct{}) error {
+	for _, env := range environ {
 		v := strings.SplitN(env, "=", 2)[0]
-		if _, found := vars[v]; !found {
-			return fmt.Errorf("unknown environment variable %s", v)
+		for _, prefix := range prefixes {
+			if !strings.HasPrefix(v, prefix) {
+				continue
+			}
+			if _, found := known[v]; !found {
+				return fmt.Errorf("unknown environment variable %s", v)
+			}
 		}
 	}
 
 	return nil
 }
 
+// checkStrictUnknownVars fails on variables starting with the prefixes set by WithStrictUnknownVars,
+// or with the prefix of the options if none, that are not read by any of vars.
+// Variables left out by the key filter are not checked, as the fields reading them were not processed.
+func checkStrictUnknownVars(vars []*variable, opts *options) error {
+	prefixes := opts.strictUnknownPrefixes
+	if len(prefixes) == 0 && opts.rootPrefix != "" {
+		prefixes = []string{opts.rootPrefix + opts.keySeparator}
+	}
+	if len(prefixes) == 0 {
+		return nil
+	}
+
+	known := make(map[string]struct{})
+	for _, v := range vars {
+		for _, name := range v.names() {
+			known[name] = struct{}{}
+			for _, fileKey := range v.fileKeys(name) {
+				known[fileKey] = struct{}{}
+			}
+		}
+	}
+
+	environ := opts.lookuper.Environ()
+	if opts.keyFilter != nil {
+		filtered := make([]string, 0, len(environ))
+		for _, env := range environ {
+			if opts.keyFilter(strings.SplitN(env, "=", 2)[0]) {
+				filtered = append(filtered, env)
+			}
+		}
+		environ = filtered
+	}
+
+	return checkUnknownVars(environ, prefixes, known)
+}
+
 // Process populates the specified struct based on environment variables
 func Process(spec any, optsValues ...Option) error {
+	return ProcessContext(context.Background(), spec, optsValues...)
+}
+
+// ProcessContext is the same as Process but gives up reading files, e.g. slow network mounted secrets,
+// once ctx is done. The error then wraps the error of the context.
+func ProcessContext(ctx context.Context, spec any, optsValues ...Option) error {
+	opts := defaultOptions().apply(optsValues...)
+	opts.ctx = ctx
+
+	return process(spec, opts)
+}
+
+// ProcessAll is the same as Process but does not stop at the first error.
+// It processes every field and returns all errors in a MultiError,
+// e.g. each ParseError and each missing required key.
+func ProcessAll(spec any, optsValues ...Option) error {
+	opts := defaultOptions().apply(optsValues...)
+	opts.allErrors = true
+
+	return process(spec, opts)
+}
+
+// ProcessPrefix is the same as Process but only populates the variables under subPrefix,
+// e.g. to reload the LOG_* variables without touching the other fields. subPrefix is joined to the prefix
+// set by WithPrefix and matches whole name segments: LOG matches APP_LOG and APP_LOG_LEVEL, not APP_LOGIN.
+// It composes with WithKeyFilter. WithStrictUnknownVars only checks the variables under subPrefix,
+// while Validate methods run on the whole spec, the fields left alone included, unless WithoutValidation is set.
+func ProcessPrefix(spec any, subPrefix string, optsValues ...Option) error {
+	opts := defaultOptions().apply(optsValues...).forSpec(spec)
+
+	subPrefix = strings.TrimSuffix(strings.TrimSpace(subPrefix), opts.keySeparator)
+	prefix := joinPrefix(opts.prefix, opts.keyCase(subPrefix), opts.keySeparator)
+	filter := opts.keyFilter
+	opts.keyFilter = func(key string) bool {
+		if key != prefix && !strings.HasPrefix(key, prefix+opts.keySeparator) {
+			return false
+		}
+		return filter == nil || filter(key)
+	}
+
+	return process(spec, opts)
+}
+
+// ProcessResult is the same as Process but also reports the value assigned to each variable and its source,
+// like Explain does without assigning, e.g. for audit logs. Values of secret fields are redacted.
+func ProcessResult(spec any, optsValues ...Option) (*Result, error) {
+	opts := defaultOptions().apply(optsValues...)
+	opts.result = &Result{}
+
+	if err := process(spec, opts); err != nil {
+		return nil, err
+	}
+
+	return opts.result, nil
+}
+
+// ProcessMany populates each of the specified structs in order using the same options.
+// The hook set by WithAfterAll is invoked once after all of them are populated.
+func ProcessMany(specs []any, optsValues ...Option) error {
 	opts := defaultOptions().apply(optsValues...)
 
+	for _, spec := range specs {
+		if err := process(spec, opts); err != nil {
+			return err
+		}
+	}
+
+	if opts.afterAll != nil {
+		return opts.afterAll()
+	}
+
+	return nil
+}
+
+func process(spec any, opts *options) error {
+	opts = opts.forSpec(spec)
+
 	vars, err := gatherInfo(spec, opts)
 	if err != nil {
 		return err
 	}
+	vars = filterVars(vars, opts)
+
+	if opts.lazyStructPointers {
+		if vars, err = resolveLazyPointers(vars); err != nil {
+			return err
+		}
+	}
+
+	if opts.validateDefaults {
+		if err = validateDefaults(vars); err != nil {
+			return err
+		}
+	}
+
+	if opts.strictTags {
+		if err = checkNameCollisions(vars); err != nil {
+			return err
+		}
+	}
+
+	if opts.processOrder == ByKey {
+		sort.SliceStable(vars, func(i, j int) bool {
+			return vars[i].key < vars[j].key
+		})
+	}
+
+	// fields defaulting to other fields are resolved once the rest is populated
+	var dependent []*variable
+
+	// required keys missing a value, reported after parse errors when checks are deferred
+	var missing []*variable
+
+	// fields missing a value, required depending on the values of their siblings
+	var conditional []*variable
+
+	// in fail-fast mode the first error is returned, otherwise all of them are collected
+	var errs []error
 
 	for _, v := range vars {
+		if _, ok := v.fieldType.Tag.Lookup(TagDefaultFrom); ok {
+			dependent = append(dependent, v)
+			continue
+		}
+
 		value, isLoaded, valueErr := v.value()
+		if valueErr == nil && !isLoaded && opts.deferRequired && v.isRequired() {
+			missing = append(missing, v)
+			continue
+		}
+		if _, ok := v.fieldType.Tag.Lookup(TagRequiredIf); ok && valueErr == nil && !isLoaded && !v.isRequired() {
+			conditional = append(conditional, v)
+			continue
+		}
+		if valueErr == nil {
+			valueErr = assign(v, value, isLoaded)
+		}
 		if valueErr != nil {
-			return valueErr
+			if !opts.allErrors {
+				return valueErr
+			}
+			errs = append(errs, valueErr)
+		}
+	}
+
+	if err = resolveDefaultsFrom(dependent); err != nil {
+		if !opts.allErrors {
+			return err
 		}
+		errs = append(errs, err)
+	}
 
-		if !isLoaded {
-			if v.isRequired() {
-				return fmt.Errorf("required key %s missing value", v.key)
+	for _, v := range conditional {
+		required, condErr := v.isRequiredIf()
+		if condErr == nil && required {
+			condErr = &RequiredError{KeyName: v.key, FieldName: v.fieldType.Name}
+		}
+		if condErr != nil {
+			if !opts.allErrors {
+				return condErr
 			}
-			continue
+			errs = append(errs, condErr)
+		}
+	}
+
+	for _, v := range missing {
+		err = assign(v, "", false)
+		if !opts.allErrors {
+			return err
 		}
+		errs = append(errs, err)
+	}
 
-		valueErr = processField(value, v.field)
-		if valueErr != nil {
-			return &ParseError{
-				KeyName:   v.key,
-				FieldName: v.fieldType.Name,
-				TypeName:  v.field.Type().String(),
-				Value:     value,
-				Err:       valueErr,
+	if len(errs) > 0 {
+		return MultiError(errs)
+	}
+
+	if opts.strictUnknown {
+		if err = checkStrictUnknownVars(vars, opts); err != nil {
+			return err
+		}
+	}
+
+	if opts.validation {
+		if err = validateSpec(reflect.ValueOf(spec).Elem(), ""); err != nil {
+			return err
+		}
+	}
+
+	if opts.result != nil {
+		opts.result.Fields = resolutions(vars)
+	}
+
+	if opts.echo != nil {
+		return echo(opts.echo, vars)
+	}
+
+	return nil
+}
+
+// assign converts and validates the value of the variable and sets the field.
+func assign(v *variable, value string, isLoaded bool) error {
+	if !isLoaded {
+		if v.isRequired() {
+			return &RequiredError{KeyName: v.key, FieldName: v.fieldType.Name}
+		}
+		return nil
+	}
+
+	return assignWith(v, value, func() error { return processField(value, v.field, v) })
+}
+
+// assignWith sets the field with set, then normalizes and validates it, so values inherited
+// through default_from are checked like converted ones.
+func assignWith(v *variable, value string, set func() error) error {
+	v.setRaw(value)
+	v.resolved = value
+
+	err := set()
+	if normalize, ok := v.Opts.normalizers[v.field.Type()]; ok && err == nil {
+		err = normalize(v.field)
+	}
+	if err == nil {
+		err = validateField(value, v)
+	}
+	if err != nil {
+		return &ParseError{
+			KeyName:     v.key,
+			BaseKeyName: v.baseKey,
+			FieldName:   v.fieldType.Name,
+			FieldPath:   v.path,
+			TypeName:    v.field.Type().String(),
+			Value:       value,
+			Secret:      v.isSecret(),
+			Err:         err,
+		}
+	}
+
+	return nil
+}
+
+// resolveDefaultsFrom populates variables tagged with default_from. Unless set in the environment,
+// such a variable takes the already resolved value of the referenced sibling field instead of its default tag.
+func resolveDefaultsFrom(vars []*variable) error {
+	const (
+		resolving = iota + 1
+		resolved
+	)
+	state := make(map[*variable]int, len(vars))
+
+	var resolve func(v *variable) error
+	resolve = func(v *variable) error {
+		switch state[v] {
+		case resolving:
+			return fmt.Errorf("cyclic default_from reference of field %s", v.fieldType.Name)
+		case resolved:
+			return nil
+		}
+		state[v] = resolving
+
+		refName := v.fieldType.Tag.Get(TagDefaultFrom)
+		ref := v.parent.FieldByName(refName)
+		if !ref.IsValid() {
+			return fmt.Errorf("default_from of field %s: unknown field %s", v.fieldType.Name, refName)
+		}
+
+		// the referenced field may itself depend on another one
+		for _, other := range vars {
+			if other.field.CanAddr() && other.field.UnsafeAddr() == ref.UnsafeAddr() {
+				if err := resolve(other); err != nil {
+					return err
+				}
 			}
 		}
+
+		// set values go through the pipe tag and the field transformers, only the default tag is replaced
+		value, isLoaded, err := v.resolve(false)
+		if err != nil {
+			return err
+		}
+
+		if isLoaded {
+			err = assign(v, value, isLoaded)
+		} else if ref.Type().AssignableTo(v.field.Type()) {
+			v.origin = SourceDefault
+			err = assignWith(v, formatValue(ref), func() error {
+				v.field.Set(ref)
+				return nil
+			})
+		} else {
+			err = fmt.Errorf("default_from of field %s: type %s of field %s is not assignable to %s",
+				v.fieldType.Name, ref.Type(), refName, v.field.Type())
+		}
+
+		state[v] = resolved
+		return err
+	}
+
+	for _, v := range vars {
+		if err := resolve(v); err != nil {
+			return err
+		}
 	}
 
-	return err
+	return nil
 }
 
 // MustProcess is the same as Process but panics if an error occurs
@@ -99,9 +423,51 @@ func MustProcess(spec any, options ...Option) {
 	}
 }
 
-func processField(value string, field reflect.Value) error {
+// ParseInto converts value to the type target points to with the same rules as Process,
+// honoring Decoder, Setter, encoding.TextUnmarshaler and encoding.BinaryUnmarshaler implementations.
+// Options affecting conversion apply, e.g. WithDefaultDelimiter or WithBoolValues.
+// Types that cannot be parsed from a string, e.g. structs without a decoder, fail with an error.
+func ParseInto(value string, target any, options ...Option) error {
+	t := reflect.ValueOf(target)
+	if t.Kind() != reflect.Ptr || t.IsNil() {
+		return ErrInvalidTarget
+	}
+
+	opts := defaultOptions().apply(options...)
+	if opts.err != nil {
+		return opts.err
+	}
+
+	if !isSupportedType(t.Elem().Type()) {
+		return fmt.Errorf("unsupported type %s", t.Elem().Type())
+	}
+
+	v := &variable{
+		field:     t.Elem(),
+		fieldType: reflect.StructField{Type: t.Elem().Type()},
+		Opts:      opts,
+	}
+
+	return processField(value, v.field, v)
+}
+
+func processField(value string, field reflect.Value, v *variable) error {
 	typ := field.Type()
 
+	if v.isJSON() {
+		return json.Unmarshal([]byte(value), field.Addr().Interface())
+	}
+
+	if decode := registeredDecoder(typ); decode != nil {
+		return decode(value, field)
+	}
+	if typ.Kind() == reflect.Ptr && registeredDecoder(typ.Elem()) != nil {
+		if field.IsNil() {
+			field.Set(reflect.New(typ.Elem()))
+		}
+		return processField(value, field.Elem(), v)
+	}
+
 	decoder := decoderFrom(field)
 	if decoder != nil {
 		return decoder.Decode(value)
@@ -112,6 +478,18 @@ func processField(value string, field reflect.Value) error {
 		return setter.Set(value)
 	}
 
+	if ok, err := netValue(value, field); ok {
+		return err
+	}
+
+	if ok, err := bigValue(value, field); ok {
+		return err
+	}
+
+	if layout := v.timeLayout(); layout != "" && (typ == timeType || typ == reflect.PointerTo(timeType)) {
+		return timeValue(value, layout, v.location, field)
+	}
+
 	if t := textUnmarshaler(field); t != nil {
 		return t.UnmarshalText([]byte(value))
 	}
@@ -121,11 +499,11 @@ func processField(value string, field reflect.Value) error {
 	}
 
 	if typ.Kind() == reflect.Ptr {
-		typ = typ.Elem()
 		if field.IsNil() {
-			field.Set(reflect.New(typ))
+			field.Set(reflect.New(typ.Elem()))
 		}
-		field = field.Elem()
+		// one level at a time, so pointers to pointers are allocated and decoders of the pointee are honored
+		return processField(value, field.Elem(), v)
 	}
 
 	switch typ.Kind() {
@@ -136,7 +514,31 @@ func processField(value string, field reflect.Value) error {
 			val int64
 			err error
 		)
-		if field.Kind() == reflect.Int64 && typ.PkgPath() == "time" && typ.Name() == "Duration" {
+		if names, ok := v.fieldType.Tag.Lookup(TagNames); ok {
+			var number string
+			number, err = namedValue(value, names)
+			if err == nil {
+				val, err = strconv.ParseInt(number, 0, typ.Bits())
+			}
+		} else if flags, ok := v.fieldType.Tag.Lookup(TagFlags); ok {
+			var number string
+			number, err = flagsValue(value, flags, v.delimiter())
+			if err == nil {
+				val, err = strconv.ParseInt(number, 0, typ.Bits())
+			}
+		} else if v.fieldType.Tag.Get(TagAs) == AsDuration {
+			var number string
+			number, err = durationValue(value, v.fieldType.Tag.Get(TagUnit))
+			if err == nil {
+				val, err = strconv.ParseInt(number, 0, typ.Bits())
+			}
+		} else if unit := v.fieldType.Tag.Get(TagUnit); unit == UnitBytes || unit == UnitPercent {
+			var number string
+			number, err = unitValue(value, unit, false)
+			if err == nil {
+				val, err = strconv.ParseInt(number, 0, typ.Bits())
+			}
+		} else if field.Kind() == reflect.Int64 && typ.PkgPath() == "time" && typ.Name() == "Duration" {
 			var d time.Duration
 			d, err = time.ParseDuration(value)
 			val = int64(d)
@@ -149,71 +551,444 @@ func processField(value string, field reflect.Value) error {
 
 		field.SetInt(val)
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		val, err := strconv.ParseUint(value, 0, typ.Bits())
+		number := value
+		if flags, ok := v.fieldType.Tag.Lookup(TagFlags); ok {
+			var err error
+			number, err = flagsValue(value, flags, v.delimiter())
+			if err != nil {
+				return err
+			}
+		} else if v.fieldType.Tag.Get(TagAs) == AsDuration {
+			var err error
+			number, err = durationValue(value, v.fieldType.Tag.Get(TagUnit))
+			if err != nil {
+				return err
+			}
+		} else if unit := v.fieldType.Tag.Get(TagUnit); unit == UnitBytes || unit == UnitPercent {
+			var err error
+			number, err = unitValue(value, unit, false)
+			if err != nil {
+				return err
+			}
+		}
+		val, err := strconv.ParseUint(number, 0, typ.Bits())
 		if err != nil {
 			return err
 		}
 		field.SetUint(val)
 	case reflect.Bool:
-		val, err := strconv.ParseBool(value)
+		val, err := v.Opts.parseBool(value)
 		if err != nil {
 			return err
 		}
 		field.SetBool(val)
 	case reflect.Float32, reflect.Float64:
-		val, err := strconv.ParseFloat(value, typ.Bits())
+		number := value
+		if unit := v.fieldType.Tag.Get(TagUnit); unit == UnitBytes || unit == UnitPercent {
+			var err error
+			number, err = unitValue(value, unit, true)
+			if err != nil {
+				return err
+			}
+		}
+		val, err := strconv.ParseFloat(number, typ.Bits())
 		if err != nil {
 			return err
 		}
 		field.SetFloat(val)
+	case reflect.Interface:
+		return interfaceValue(value, field)
+	case reflect.Complex64, reflect.Complex128:
+		val, err := strconv.ParseComplex(value, typ.Bits())
+		if err != nil {
+			return err
+		}
+		field.SetComplex(val)
 	case reflect.Slice:
 		sl := reflect.MakeSlice(typ, 0, 0)
 		if typ.Elem().Kind() == reflect.Uint8 {
-			sl = reflect.ValueOf([]byte(value))
+			b, err := decodeBytes(value, v.fieldType.Tag.Get(TagEncoding))
+			if err != nil {
+				return err
+			}
+			sl = reflect.ValueOf(b).Convert(typ)
 		} else if strings.TrimSpace(value) != "" {
-			vals := strings.Split(value, ",")
-			sl = reflect.MakeSlice(typ, len(vals), len(vals))
-			for i, val := range vals {
-				err := processField(val, sl.Index(i))
+			keepEmpty := isTrue(v.fieldType.Tag.Get(TagKeepEmpty))
+			vals, err := v.splitElems(value)
+			if err != nil {
+				return err
+			}
+			sl = reflect.MakeSlice(typ, 0, len(vals))
+			for _, val := range vals {
+				// empty elements are dropped unless asked to be kept as zero values
+				if strings.TrimSpace(val) == "" {
+					if keepEmpty {
+						sl = reflect.Append(sl, reflect.Zero(typ.Elem()))
+					}
+					continue
+				}
+				// elements honor the tags of the field, e.g. time_format or as
+				elem := reflect.New(typ.Elem()).Elem()
+				err := processField(val, elem, v)
 				if err != nil {
 					return err
 				}
+				sl = reflect.Append(sl, elem)
 			}
 		}
 		field.Set(sl)
+	case reflect.Array:
+		// parsed like a slice, which must fill the array unless the value is empty
+		sl := reflect.New(reflect.SliceOf(typ.Elem())).Elem()
+		if err := processField(value, sl, v); err != nil {
+			return err
+		}
+		if sl.Len() == 0 {
+			field.Set(reflect.Zero(typ))
+			return nil
+		}
+		if sl.Len() != typ.Len() {
+			return fmt.Errorf("expected %d elements, got %d", typ.Len(), sl.Len())
+		}
+		reflect.Copy(field, sl)
 	case reflect.Map:
 		mp := reflect.MakeMap(typ)
-		if strings.TrimSpace(value) != "" {
-			pairs := strings.Split(value, ",")
+		// slice values are split on the value delimiter, the delimiter separates pairs
+		elemVar := *v
+		elemVar.elemDelimiter = v.valueDelimiter()
+		if strings.TrimSpace(value) != "" && !v.isEmptyMapToken(value) {
+			pairs := strings.Split(value, v.delimiter())
 			for _, pair := range pairs {
-				kvpair := strings.Split(pair, ":")
+				kvpair := strings.SplitN(pair, v.mapSeparator(), 2)
 				if len(kvpair) != 2 {
 					return fmt.Errorf("invalid map item: %q", pair)
 				}
 				k := reflect.New(typ.Key()).Elem()
-				err := processField(kvpair[0], k)
+				err := processField(kvpair[0], k, v)
 				if err != nil {
 					return err
 				}
-				v := reflect.New(typ.Elem()).Elem()
-				err = processField(kvpair[1], v)
+				e := reflect.New(typ.Elem()).Elem()
+				err = processField(kvpair[1], e, &elemVar)
 				if err != nil {
 					return err
 				}
-				mp.SetMapIndex(k, v)
+				mp.SetMapIndex(k, e)
 			}
 		}
 		field.Set(mp)
+	default:
+		if v.Opts.errorOnUnsupported {
+			return fmt.Errorf("unsupported type %s", typ)
+		}
 	}
 
 	return nil
 }
 
+// decodeBytes decodes value according to the encoding tag, raw bytes of the value are used if it is empty.
+func decodeBytes(value, encoding string) ([]byte, error) {
+	switch encoding {
+	case "":
+		return []byte(value), nil
+	case EncodingBase64:
+		return base64.StdEncoding.DecodeString(value)
+	case EncodingHex:
+		return hex.DecodeString(value)
+	}
+
+	return nil, fmt.Errorf("unknown encoding %q", encoding)
+}
+
+// durationUnits maps unit tag values to durations
+var durationUnits = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"µs": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+}
+
+// durationValue parses value as a time.Duration and returns it as an integer count of unit, seconds by default.
+// Plain integers are taken as already expressed in unit.
+func durationValue(value, unit string) (string, error) {
+	if unit == "" {
+		unit = "s"
+	}
+	size, found := durationUnits[unit]
+	if !found {
+		return "", fmt.Errorf("unknown duration unit %q", unit)
+	}
+
+	if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return value, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return "", err
+	}
+
+	return strconv.FormatInt(int64(d/size), 10), nil
+}
+
+// types parsed explicitly by processField
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	ipType       = reflect.TypeOf(net.IP{})
+	ipNetType    = reflect.TypeOf(net.IPNet{})
+	urlType      = reflect.TypeOf(url.URL{})
+	bigIntType   = reflect.TypeOf(big.Int{})
+	bigFloatType = reflect.TypeOf(big.Float{})
+)
+
+// bigValue parses value into big.Int and big.Float fields or pointers to them.
+// Integers may have a base prefix like 0x. It reports whether the field is of one of those types.
+func bigValue(value string, field reflect.Value) (bool, error) {
+	typ := field.Type()
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ != bigIntType && typ != bigFloatType {
+		return false, nil
+	}
+
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			field.Set(reflect.New(typ))
+		}
+		field = field.Elem()
+	}
+
+	switch n := field.Addr().Interface().(type) {
+	case *big.Int:
+		if _, ok := n.SetString(value, 0); !ok {
+			return true, fmt.Errorf("invalid integer %q", value)
+		}
+	case *big.Float:
+		if _, ok := n.SetString(value); !ok {
+			return true, fmt.Errorf("invalid float %q", value)
+		}
+	}
+
+	return true, nil
+}
+
+// netValue parses value into net.IP, net.IPNet and url.URL fields or pointers to them.
+// It reports whether the field is of one of those types.
+func netValue(value string, field reflect.Value) (bool, error) {
+	typ := field.Type()
+	if typ.Kind() == reflect.Ptr && (typ.Elem() == ipNetType || typ.Elem() == urlType) {
+		typ = typ.Elem()
+	}
+
+	var parsed any
+	switch typ {
+	case ipType:
+		ip := net.ParseIP(value)
+		if ip == nil {
+			return true, fmt.Errorf("invalid IP address %q", value)
+		}
+		parsed = ip
+	case ipNetType:
+		_, ipNet, err := net.ParseCIDR(value)
+		if err != nil {
+			return true, err
+		}
+		parsed = *ipNet
+	case urlType:
+		u, err := url.Parse(value)
+		if err != nil {
+			return true, err
+		}
+		parsed = *u
+	default:
+		return false, nil
+	}
+
+	if field.Kind() == reflect.Ptr {
+		field.Set(reflect.New(typ))
+		field = field.Elem()
+	}
+	field.Set(reflect.ValueOf(parsed))
+
+	return true, nil
+}
+
+// timeValue parses value with layout into a time.Time or *time.Time field.
+// Values without zone information are interpreted in location, UTC if it is nil.
+// An empty value leaves the field zero.
+func timeValue(value, layout string, location *time.Location, field reflect.Value) error {
+	if value == "" {
+		return nil
+	}
+	if location == nil {
+		location = time.UTC
+	}
+
+	t, err := time.ParseInLocation(layout, value, location)
+	if err != nil {
+		return err
+	}
+
+	if field.Kind() == reflect.Ptr {
+		field.Set(reflect.New(timeType))
+		field = field.Elem()
+	}
+	field.Set(reflect.ValueOf(t))
+
+	return nil
+}
+
+// byteSizes maps suffixes of byte sizes, lower cased, to their multiples: kB, MB... are decimal, KiB, MiB... binary.
+var byteSizes = map[string]uint64{
+	"":    1,
+	"b":   1,
+	"kb":  1e3,
+	"mb":  1e6,
+	"gb":  1e9,
+	"tb":  1e12,
+	"pb":  1e15,
+	"kib": 1 << 10,
+	"mib": 1 << 20,
+	"gib": 1 << 30,
+	"tib": 1 << 40,
+	"pib": 1 << 50,
+}
+
+// unitValue converts a human-readable quantity of the unit tag to a plain number, see bytesValue and percentValue.
+func unitValue(value, unit string, fraction bool) (string, error) {
+	if unit == UnitPercent {
+		return percentValue(value, fraction)
+	}
+
+	return bytesValue(value, fraction)
+}
+
+// bytesValue converts a byte size like 512MiB or 1.5GB to a number of bytes, suffixes are case insensitive.
+// Values without suffix are taken as bytes. Sizes must not be negative, and must be a whole number of bytes
+// unless fraction is set, as for float fields.
+func bytesValue(value string, fraction bool) (string, error) {
+	i := strings.IndexFunc(value, unicode.IsLetter)
+	if i < 0 || strings.HasPrefix(value, "0x") || strings.HasPrefix(value, "0X") {
+		return value, nil
+	}
+
+	number, suffix := strings.TrimSpace(value[:i]), value[i:]
+	size, found := byteSizes[strings.ToLower(suffix)]
+	if !found {
+		return "", fmt.Errorf("unknown byte size unit %q", suffix)
+	}
+
+	if n, err := strconv.ParseUint(number, 10, 64); err == nil {
+		if n > math.MaxUint64/size {
+			return "", fmt.Errorf("byte size %q overflows", value)
+		}
+		return strconv.FormatUint(n*size, 10), nil
+	}
+
+	f, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid byte size %q", value)
+	}
+	if f < 0 {
+		return "", fmt.Errorf("byte size %q is negative", value)
+	}
+	f *= float64(size)
+	if !fraction && f != math.Trunc(f) {
+		return "", fmt.Errorf("byte size %q is not a whole number of bytes", value)
+	}
+
+	return strconv.FormatFloat(f, 'f', -1, 64), nil
+}
+
+// percentValue strips the % sign of a percentage. With fraction, as for float fields, 5% is converted to 0.05,
+// otherwise it is 5. Values without % sign are returned as is.
+func percentValue(value string, fraction bool) (string, error) {
+	number, found := strings.CutSuffix(value, "%")
+	if !found {
+		return value, nil
+	}
+	number = strings.TrimSpace(number)
+	if !fraction {
+		return number, nil
+	}
+
+	f, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return "", err
+	}
+
+	return strconv.FormatFloat(f/100, 'f', -1, 64), nil
+}
+
+// namedValue resolves value against a `name=number` list from the names tag.
+// Names are matched case-insensitively, plain numbers must be one of the listed numbers.
+func namedValue(value, names string) (string, error) {
+	raw, rawErr := strconv.ParseInt(value, 0, 64)
+	var allowed []string
+	for _, item := range strings.Split(names, ",") {
+		name, number, found := strings.Cut(item, "=")
+		if !found {
+			return "", fmt.Errorf("invalid names item: %q", item)
+		}
+		name, number = strings.TrimSpace(name), strings.TrimSpace(number)
+		allowed = append(allowed, name)
+		if strings.EqualFold(name, value) {
+			return number, nil
+		}
+		// a number is accepted only if it is one of the listed values
+		if n, err := strconv.ParseInt(number, 0, 64); rawErr == nil && err == nil && n == raw {
+			return number, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown name %q, expected one of %s", value, strings.Join(allowed, ", "))
+}
+
+// flagsValue ORs together the values of the flag names separated by the delimiter
+// according to a `name=number` list from the flags tag.
+func flagsValue(value, flags, delimiter string) (string, error) {
+	known := make(map[string]uint64)
+	for _, item := range strings.Split(flags, ",") {
+		name, number, found := strings.Cut(item, "=")
+		if !found {
+			return "", fmt.Errorf("invalid flags item: %q", item)
+		}
+		bits, err := strconv.ParseUint(strings.TrimSpace(number), 0, 64)
+		if err != nil {
+			return "", fmt.Errorf("invalid flags item: %q", item)
+		}
+		known[strings.ToLower(strings.TrimSpace(name))] = bits
+	}
+
+	var result uint64
+	for _, name := range strings.Split(value, delimiter) {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		bits, found := known[strings.ToLower(name)]
+		if !found {
+			return "", fmt.Errorf("unknown flag %q, expected any of %q", name, flags)
+		}
+		result |= bits
+	}
+
+	return strconv.FormatUint(result, 10), nil
+}
+
 func interfaceFrom(field reflect.Value, fn func(interface{}, *bool)) {
 	// it may be impossible for a struct field to fail this check
 	if !field.CanInterface() {
 		return
 	}
+	// methods of nil pointers are reached once allocated, see the pointer case of processField
+	if field.Kind() == reflect.Ptr && field.IsNil() {
+		return
+	}
 	var ok bool
 	fn(field.Interface(), &ok)
 	if !ok && field.CanAddr() {
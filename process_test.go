@@ -1,11 +1,17 @@
 package envconfig
 
 import (
+	"bytes"
+	"context"
+	"encoding/hex"
 	"errors"
 	"flag"
 	"fmt"
+	"math/big"
+	"net"
 	"net/url"
 	"os"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -961,3 +967,1905 @@ func BenchmarkGatherInfo(b *testing.B) {
 		gatherInfo(&s, opts)
 	}
 }
+
+type logLevel int
+
+func TestNamedIntegers(t *testing.T) {
+	var s struct {
+		Level  logLevel   `names:"debug=0,info=1,warn=2,error=3"`
+		Levels []logLevel `names:"debug=0,info=1,warn=2,error=3"`
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_LEVEL", "info")
+	os.Setenv("ENV_CONFIG_LEVELS", "WARN,0")
+
+	err := Process(&s, WithPrefix("env_config"))
+	assert.NoError(t, err)
+	assert.Equal(t, logLevel(1), s.Level)
+	assert.Equal(t, []logLevel{2, 0}, s.Levels)
+
+	os.Setenv("ENV_CONFIG_LEVEL", "1")
+	err = Process(&s, WithPrefix("env_config"))
+	assert.NoError(t, err)
+	assert.Equal(t, logLevel(1), s.Level)
+
+	os.Setenv("ENV_CONFIG_LEVEL", "verbose")
+	err = Process(&s, WithPrefix("env_config"))
+	v, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("expected ParseError, got %T %v", err, err)
+	}
+	assert.Equal(t, "Level", v.FieldName)
+	assert.Contains(t, v.Err.Error(), `unknown name "verbose"`)
+
+	os.Setenv("ENV_CONFIG_LEVEL", "7")
+	err = Process(&s, WithPrefix("env_config"))
+	v, ok = err.(*ParseError)
+	if !ok {
+		t.Fatalf("expected ParseError, got %T %v", err, err)
+	}
+	assert.Equal(t, "Level", v.FieldName)
+	assert.EqualError(t, v.Err, `unknown name "7", expected one of debug, info, warn, error`)
+}
+
+func TestProcessManyAfterAll(t *testing.T) {
+	var logging struct {
+		Level string
+	}
+	var tracing struct {
+		Enabled bool
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_LEVEL", "debug")
+	os.Setenv("ENV_CONFIG_ENABLED", "true")
+
+	calls := 0
+	err := ProcessMany([]any{&logging, &tracing}, WithPrefix("env_config"), WithAfterAll(func() error {
+		calls++
+		assert.Equal(t, "debug", logging.Level)
+		assert.True(t, tracing.Enabled)
+		return nil
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, calls)
+
+	hookErr := errors.New("tracing requires debug logging")
+	err = ProcessMany([]any{&logging, &tracing}, WithPrefix("env_config"), WithAfterAll(func() error {
+		return hookErr
+	}))
+	assert.Same(t, hookErr, err)
+}
+
+func TestSliceEmptyElements(t *testing.T) {
+	type spec struct {
+		Strings         []string
+		Ints            []int
+		KeptStrings     []string `keep_empty:"true"`
+		KeptInts        []int    `keep_empty:"true"`
+		ExplicitDropped []int    `keep_empty:"false"`
+	}
+
+	tests := []struct {
+		value    string
+		strings  []string
+		ints     []int
+		kStrings []string
+		kInts    []int
+	}{
+		{
+			value:    "1,,2",
+			strings:  []string{"1", "2"},
+			ints:     []int{1, 2},
+			kStrings: []string{"1", "", "2"},
+			kInts:    []int{1, 0, 2},
+		},
+		{
+			value:    "1,2,",
+			strings:  []string{"1", "2"},
+			ints:     []int{1, 2},
+			kStrings: []string{"1", "2", ""},
+			kInts:    []int{1, 2, 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			var s spec
+
+			os.Clearenv()
+			for _, key := range []string{"STRINGS", "INTS", "KEPTSTRINGS", "KEPTINTS", "EXPLICITDROPPED"} {
+				os.Setenv("ENV_CONFIG_"+key, tt.value)
+			}
+
+			err := Process(&s, WithPrefix("env_config"))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.strings, s.Strings)
+			assert.Equal(t, tt.ints, s.Ints)
+			assert.Equal(t, tt.kStrings, s.KeptStrings)
+			assert.Equal(t, tt.kInts, s.KeptInts)
+			assert.Equal(t, tt.ints, s.ExplicitDropped)
+		})
+	}
+}
+
+func TestProcessOrder(t *testing.T) {
+	var s struct {
+		Zulu  string `required:"true"`
+		Alpha string `required:"true"`
+	}
+
+	os.Clearenv()
+
+	err := Process(&s, WithPrefix("env_config"))
+	assert.EqualError(t, err, "required key ENV_CONFIG_ZULU missing value")
+
+	err = Process(&s, WithPrefix("env_config"), WithProcessOrder(ByDeclaration))
+	assert.EqualError(t, err, "required key ENV_CONFIG_ZULU missing value")
+
+	err = Process(&s, WithPrefix("env_config"), WithProcessOrder(ByKey))
+	assert.EqualError(t, err, "required key ENV_CONFIG_ALPHA missing value")
+}
+
+type fileFlags uint8
+
+func TestFlagsIntegers(t *testing.T) {
+	var s struct {
+		Perms fileFlags `flags:"read=4,write=2,exec=1"`
+		Caps  int       `flags:"net=0x1,fs=0x2,ipc=0x4"`
+		Modes uint      `flags:"a=1,b=2" delimiter:"|"`
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_PERMS", "read,write")
+	os.Setenv("ENV_CONFIG_CAPS", "NET, ipc")
+	os.Setenv("ENV_CONFIG_MODES", "a|b")
+
+	err := Process(&s, WithPrefix("env_config"))
+	assert.NoError(t, err)
+	assert.Equal(t, fileFlags(6), s.Perms)
+	assert.Equal(t, 5, s.Caps)
+	assert.Equal(t, uint(3), s.Modes)
+
+	os.Setenv("ENV_CONFIG_PERMS", "read,delete")
+	err = Process(&s, WithPrefix("env_config"))
+	v, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("expected ParseError, got %T %v", err, err)
+	}
+	assert.Equal(t, "Perms", v.FieldName)
+	assert.Contains(t, v.Err.Error(), `unknown flag "delete"`)
+}
+
+func TestEncodedBytesDefault(t *testing.T) {
+	var s struct {
+		Key  []byte `encoding:"hex" default:"deadbeef"`
+		Salt []byte `encoding:"base64" default:"c2FsdA=="`
+		Raw  []byte `default:"deadbeef"`
+	}
+
+	os.Clearenv()
+
+	err := Process(&s, WithPrefix("env_config"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0xde, 0xad, 0xbe, 0xef}, s.Key)
+	assert.Equal(t, []byte("salt"), s.Salt)
+	assert.Equal(t, []byte("deadbeef"), s.Raw)
+
+	os.Setenv("ENV_CONFIG_KEY", "cafe")
+	err = Process(&s, WithPrefix("env_config"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{0xca, 0xfe}, s.Key)
+}
+
+type seconds int
+
+func TestIntegerAsDuration(t *testing.T) {
+	var s struct {
+		Timeout  seconds `as:"duration"`
+		Interval int64   `as:"duration" unit:"ms"`
+		TTL      uint32  `as:"duration" default:"1h"`
+		Plain    int     `as:"duration"`
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_TIMEOUT", "5m")
+	os.Setenv("ENV_CONFIG_INTERVAL", "5m")
+	os.Setenv("ENV_CONFIG_PLAIN", "42")
+
+	err := Process(&s, WithPrefix("env_config"))
+	assert.NoError(t, err)
+	assert.Equal(t, seconds(300), s.Timeout)
+	assert.Equal(t, int64(300000), s.Interval)
+	assert.Equal(t, uint32(3600), s.TTL)
+	assert.Equal(t, 42, s.Plain)
+
+	os.Setenv("ENV_CONFIG_TIMEOUT", "5 minutes")
+	err = Process(&s, WithPrefix("env_config"))
+	v, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("expected ParseError, got %T %v", err, err)
+	}
+	assert.Equal(t, "Timeout", v.FieldName)
+}
+
+func TestDefaultFrom(t *testing.T) {
+	type spec struct {
+		MetricsEnabled       bool `default_from:"ObservabilityEnabled"`
+		TracingEnabled       bool `default_from:"MetricsEnabled"`
+		ObservabilityEnabled bool `default:"true"`
+	}
+
+	t.Run("inherited", func(t *testing.T) {
+		var s spec
+		os.Clearenv()
+
+		err := Process(&s, WithPrefix("env_config"))
+		assert.NoError(t, err)
+		assert.True(t, s.ObservabilityEnabled)
+		assert.True(t, s.MetricsEnabled)
+		assert.True(t, s.TracingEnabled)
+	})
+
+	t.Run("overridden", func(t *testing.T) {
+		var s spec
+		os.Clearenv()
+		os.Setenv("ENV_CONFIG_METRICSENABLED", "false")
+
+		err := Process(&s, WithPrefix("env_config"))
+		assert.NoError(t, err)
+		assert.True(t, s.ObservabilityEnabled)
+		assert.False(t, s.MetricsEnabled)
+		assert.False(t, s.TracingEnabled)
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		var s struct {
+			A bool `default_from:"B"`
+			B bool `default_from:"A"`
+		}
+		os.Clearenv()
+
+		err := Process(&s, WithPrefix("env_config"))
+		assert.EqualError(t, err, "cyclic default_from reference of field A")
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		var s struct {
+			A bool `default_from:"Missing"`
+		}
+		os.Clearenv()
+
+		err := Process(&s, WithPrefix("env_config"))
+		assert.EqualError(t, err, "default_from of field A: unknown field Missing")
+	})
+
+	t.Run("unexported field", func(t *testing.T) {
+		var s struct {
+			A    string `default_from:"mode"`
+			mode string
+		}
+		s.mode = "x"
+		os.Clearenv()
+
+		err := Process(&s, WithPrefix("env_config"))
+		assert.ErrorIs(t, err, ErrInvalidSpecification)
+		assert.ErrorContains(t, err, "default_from of field A: unexported field mode")
+	})
+
+	t.Run("validated", func(t *testing.T) {
+		var s struct {
+			Mode    string `default:"x"`
+			Backup  string `default_from:"Mode" oneof:"y"`
+			Primary string `default_from:"Mode"`
+			Raw     string `raw:"Primary"`
+		}
+		os.Clearenv()
+
+		err := Process(&s, WithPrefix("env_config"))
+		v, ok := err.(*ParseError)
+		if !ok {
+			t.Fatalf("expected ParseError, got %T %v", err, err)
+		}
+		assert.Equal(t, "Backup", v.FieldName)
+		assert.EqualError(t, v.Err, `must be one of y, got "x"`)
+
+		os.Setenv("ENV_CONFIG_MODE", "y")
+		assert.NoError(t, Process(&s, WithPrefix("env_config")))
+		assert.Equal(t, "y", s.Backup)
+		assert.Equal(t, "y", s.Raw)
+	})
+}
+
+func TestProcessKeyFilter(t *testing.T) {
+	var s struct {
+		Port  int
+		Level string `required:"true"`
+	}
+
+	os.Clearenv()
+	os.Setenv("APP_PORT", "8080")
+	os.Setenv("APP_LEVEL", "debug")
+
+	err := Process(&s, WithPrefix("app"), WithKeyFilter(func(key string) bool {
+		return key == "APP_PORT"
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, 8080, s.Port)
+	assert.Equal(t, "", s.Level)
+}
+
+func TestEmptyMapTokens(t *testing.T) {
+	type spec struct {
+		Labels map[string]string `default:"team:core,tier:1"`
+	}
+
+	tests := []struct {
+		name     string
+		value    string
+		opts     []Option
+		expected map[string]string
+	}{
+		{
+			name:     "braces",
+			value:    "{}",
+			expected: map[string]string{},
+		},
+		{
+			name:     "none",
+			value:    " NONE ",
+			expected: map[string]string{},
+		},
+		{
+			name:     "populated",
+			value:    "team:edge",
+			expected: map[string]string{"team": "edge"},
+		},
+		{
+			name:     "custom token",
+			value:    "-",
+			opts:     []Option{WithEmptyMapTokens("-")},
+			expected: map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s spec
+
+			os.Clearenv()
+			os.Setenv("ENV_CONFIG_LABELS", tt.value)
+
+			err := Process(&s, append([]Option{WithPrefix("env_config"), WithoutTrimSpaces()}, tt.opts...)...)
+			assert.NoError(t, err)
+			assert.NotNil(t, s.Labels)
+			assert.Equal(t, tt.expected, s.Labels)
+		})
+	}
+
+	t.Run("custom tokens replace defaults", func(t *testing.T) {
+		var s spec
+
+		os.Clearenv()
+		os.Setenv("ENV_CONFIG_LABELS", "none")
+
+		err := Process(&s, WithPrefix("env_config"), WithEmptyMapTokens("-"))
+		assert.Error(t, err)
+	})
+}
+
+type email string
+
+func TestNormalizer(t *testing.T) {
+	var s struct {
+		Admin   email
+		Support email `default:"Support@Example.COM"`
+		Name    string
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_ADMIN", "Admin@Example.com")
+	os.Setenv("ENV_CONFIG_NAME", "Mixed Case")
+
+	lower := func(v reflect.Value) error {
+		v.SetString(strings.ToLower(v.String()))
+		return nil
+	}
+
+	err := Process(&s, WithPrefix("env_config"), WithNormalizer(reflect.TypeOf(email("")), lower))
+	assert.NoError(t, err)
+	assert.Equal(t, email("admin@example.com"), s.Admin)
+	assert.Equal(t, email("support@example.com"), s.Support)
+	assert.Equal(t, "Mixed Case", s.Name)
+
+	failing := func(v reflect.Value) error {
+		return errors.New("not allowed")
+	}
+
+	err = Process(&s, WithPrefix("env_config"), WithNormalizer(reflect.TypeOf(email("")), failing))
+	v, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("expected ParseError, got %T %v", err, err)
+	}
+	assert.Equal(t, "Admin", v.FieldName)
+}
+
+func TestDelimiter(t *testing.T) {
+	type spec struct {
+		Paths  []string `delimiter:";"`
+		Hosts  []string
+		Labels map[string]string `delimiter:";"`
+		Ports  map[string]int
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_PATHS", "/a,b;/c")
+	os.Setenv("ENV_CONFIG_HOSTS", "a,b|c")
+	os.Setenv("ENV_CONFIG_LABELS", "team:core,edge;tier:1")
+	os.Setenv("ENV_CONFIG_PORTS", "http:80,https:443")
+
+	var s spec
+	err := Process(&s, WithPrefix("env_config"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/a,b", "/c"}, s.Paths)
+	assert.Equal(t, []string{"a", "b|c"}, s.Hosts)
+	assert.Equal(t, map[string]string{"team": "core,edge", "tier": "1"}, s.Labels)
+	assert.Equal(t, map[string]int{"http": 80, "https": 443}, s.Ports)
+
+	os.Setenv("ENV_CONFIG_HOSTS", "a,b|c")
+	os.Setenv("ENV_CONFIG_PORTS", "http:80|https:443")
+
+	s = spec{}
+	err = Process(&s, WithPrefix("env_config"), WithDefaultDelimiter("|"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/a,b", "/c"}, s.Paths)
+	assert.Equal(t, []string{"a,b", "c"}, s.Hosts)
+	assert.Equal(t, map[string]int{"http": 80, "https": 443}, s.Ports)
+
+	os.Setenv("ENV_CONFIG_PORTS", "http:80,https:443")
+
+	s = spec{}
+	err = Process(&s, WithPrefix("env_config"), WithDefaultDelimiter(""))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b|c"}, s.Hosts)
+}
+
+func TestRawCompanion(t *testing.T) {
+	var s struct {
+		Timeout    time.Duration
+		TimeoutRaw string `raw:"Timeout"`
+		Retries    int    `default:"3"`
+		RetriesRaw string `raw:"Retries"`
+		Unset      int
+		UnsetRaw   string `raw:"Unset"`
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_TIMEOUT", "5m")
+	os.Setenv("ENV_CONFIG_TIMEOUTRAW", "ignored")
+
+	err := Process(&s, WithPrefix("env_config"))
+	assert.NoError(t, err)
+	assert.Equal(t, 5*time.Minute, s.Timeout)
+	assert.Equal(t, "5m", s.TimeoutRaw)
+	assert.Equal(t, 3, s.Retries)
+	assert.Equal(t, "3", s.RetriesRaw)
+	assert.Equal(t, "", s.UnsetRaw)
+}
+
+func TestMapSeparator(t *testing.T) {
+	type spec struct {
+		Endpoints map[string]string
+		Weights   map[string]int `map_separator:"="`
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_ENDPOINTS", "url1:https://example.com,url2:http://localhost:8080")
+	os.Setenv("ENV_CONFIG_WEIGHTS", "a=1,b=2")
+
+	var s spec
+	err := Process(&s, WithPrefix("env_config"))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"url1": "https://example.com", "url2": "http://localhost:8080"}, s.Endpoints)
+	assert.Equal(t, map[string]int{"a": 1, "b": 2}, s.Weights)
+
+	os.Setenv("ENV_CONFIG_ENDPOINTS", "url1=>https://example.com")
+
+	s = spec{}
+	err = Process(&s, WithPrefix("env_config"), WithMapSeparator("=>"))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"url1": "https://example.com"}, s.Endpoints)
+	assert.Equal(t, map[string]int{"a": 1, "b": 2}, s.Weights)
+
+	os.Setenv("ENV_CONFIG_ENDPOINTS", "url1")
+
+	err = Process(&s, WithPrefix("env_config"))
+	v, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("expected ParseError, got %T %v", err, err)
+	}
+	assert.EqualError(t, v.Err, `invalid map item: "url1"`)
+}
+
+func TestProcessAll(t *testing.T) {
+	var s struct {
+		Port     int
+		Debug    bool
+		Host     string `required:"true"`
+		Timeout  time.Duration
+		Required string `required:"true"`
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_PORT", "eighty")
+	os.Setenv("ENV_CONFIG_DEBUG", "maybe")
+	os.Setenv("ENV_CONFIG_TIMEOUT", "5m")
+	os.Setenv("ENV_CONFIG_REQUIRED", "set")
+
+	err := Process(&s, WithPrefix("env_config"))
+	v, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("expected ParseError, got %T %v", err, err)
+	}
+	assert.Equal(t, "Port", v.FieldName)
+
+	err = ProcessAll(&s, WithPrefix("env_config"))
+	if !assert.Error(t, err) {
+		return
+	}
+
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		t.Fatalf("expected joined errors, got %T %v", err, err)
+	}
+	errs := joined.Unwrap()
+	if assert.Len(t, errs, 3) {
+		assert.Equal(t, "Port", errs[0].(*ParseError).FieldName)
+		assert.Equal(t, "Debug", errs[1].(*ParseError).FieldName)
+		assert.EqualError(t, errs[2], "required key ENV_CONFIG_HOST missing value")
+	}
+
+	var parseErr *ParseError
+	assert.True(t, errors.As(err, &parseErr))
+	assert.Equal(t, 5*time.Minute, s.Timeout)
+	assert.Equal(t, "set", s.Required)
+
+	os.Setenv("ENV_CONFIG_PORT", "80")
+	os.Setenv("ENV_CONFIG_DEBUG", "true")
+	os.Setenv("ENV_CONFIG_HOST", "localhost")
+	assert.NoError(t, ProcessAll(&s, WithPrefix("env_config")))
+}
+
+func TestTimeFormat(t *testing.T) {
+	var s struct {
+		Date     time.Time  `time_format:"2006-01-02"`
+		Expires  *time.Time `time_format:"02.01.2006"`
+		Started  time.Time  `time_format:"2006-01-02" default:"2024-03-01"`
+		Stamp    time.Time
+		Holidays []time.Time `time_format:"2006-01-02"`
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_DATE", "2024-02-29")
+	os.Setenv("ENV_CONFIG_EXPIRES", "31.12.2025")
+	os.Setenv("ENV_CONFIG_STAMP", "2024-01-02T03:04:05Z")
+	os.Setenv("ENV_CONFIG_HOLIDAYS", "2024-01-01,2024-12-25")
+
+	if assert.NoError(t, Process(&s, WithPrefix("env_config"))) {
+		assert.Equal(t, time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), s.Date)
+		if assert.NotNil(t, s.Expires) {
+			assert.Equal(t, time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC), *s.Expires)
+		}
+		assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), s.Started)
+		assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), s.Stamp)
+		assert.Equal(t, []time.Time{
+			time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2024, 12, 25, 0, 0, 0, 0, time.UTC),
+		}, s.Holidays)
+	}
+
+	os.Setenv("ENV_CONFIG_STAMP", "02/01/2024")
+	if assert.NoError(t, Process(&s, WithPrefix("env_config"), WithTimeLayout("02/01/2006"))) {
+		assert.Equal(t, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), s.Stamp)
+		assert.Equal(t, time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC), s.Date)
+	}
+
+	os.Setenv("ENV_CONFIG_DATE", "29.02.2024")
+	err := Process(&s, WithPrefix("env_config"))
+	v, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("expected ParseError, got %T %v", err, err)
+	}
+	assert.Equal(t, "Date", v.FieldName)
+	assert.Equal(t, "time.Time", v.TypeName)
+	assert.Equal(t, "29.02.2024", v.Value)
+}
+
+func TestDeferredRequiredChecks(t *testing.T) {
+	var s struct {
+		Host  string `required:"true"`
+		Port  int
+		Debug bool
+		Token string `required:"true"`
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_PORT", "eighty")
+	os.Setenv("ENV_CONFIG_DEBUG", "maybe")
+
+	err := Process(&s, WithPrefix("env_config"))
+	assert.EqualError(t, err, "required key ENV_CONFIG_HOST missing value")
+
+	err = Process(&s, WithPrefix("env_config"), WithDeferredRequiredChecks())
+	v, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("expected ParseError, got %T %v", err, err)
+	}
+	assert.Equal(t, "Port", v.FieldName)
+
+	errs := ProcessAll(&s, WithPrefix("env_config")).(interface{ Unwrap() []error }).Unwrap()
+	if assert.Len(t, errs, 4) {
+		assert.EqualError(t, errs[0], "required key ENV_CONFIG_HOST missing value")
+		assert.Equal(t, "Port", errs[1].(*ParseError).FieldName)
+		assert.Equal(t, "Debug", errs[2].(*ParseError).FieldName)
+		assert.EqualError(t, errs[3], "required key ENV_CONFIG_TOKEN missing value")
+	}
+
+	errs = ProcessAll(&s, WithPrefix("env_config"), WithDeferredRequiredChecks()).(interface{ Unwrap() []error }).Unwrap()
+	if assert.Len(t, errs, 4) {
+		assert.Equal(t, "Port", errs[0].(*ParseError).FieldName)
+		assert.Equal(t, "Debug", errs[1].(*ParseError).FieldName)
+		assert.EqualError(t, errs[2], "required key ENV_CONFIG_HOST missing value")
+		assert.EqualError(t, errs[3], "required key ENV_CONFIG_TOKEN missing value")
+	}
+
+	os.Setenv("ENV_CONFIG_PORT", "80")
+	os.Setenv("ENV_CONFIG_DEBUG", "true")
+	err = Process(&s, WithPrefix("env_config"), WithDeferredRequiredChecks())
+	assert.EqualError(t, err, "required key ENV_CONFIG_HOST missing value")
+}
+
+func TestNetTypes(t *testing.T) {
+	var s struct {
+		Address net.IP
+		Subnet  *net.IPNet
+		Network net.IPNet
+		Proxy   url.URL
+		Backup  *url.URL
+		DNS     []net.IP
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_ADDRESS", "10.0.0.1")
+	os.Setenv("ENV_CONFIG_SUBNET", "10.0.0.0/8")
+	os.Setenv("ENV_CONFIG_NETWORK", "fd00::/64")
+	os.Setenv("ENV_CONFIG_PROXY", "http://proxy:3128")
+	os.Setenv("ENV_CONFIG_BACKUP", "https://backup.example.com/path")
+	os.Setenv("ENV_CONFIG_DNS", "8.8.8.8,1.1.1.1")
+
+	if assert.NoError(t, Process(&s, WithPrefix("env_config"))) {
+		assert.Equal(t, "10.0.0.1", s.Address.String())
+		if assert.NotNil(t, s.Subnet) {
+			assert.Equal(t, "10.0.0.0/8", s.Subnet.String())
+		}
+		assert.Equal(t, "fd00::/64", s.Network.String())
+		assert.Equal(t, "proxy:3128", s.Proxy.Host)
+		if assert.NotNil(t, s.Backup) {
+			assert.Equal(t, "/path", s.Backup.Path)
+		}
+		assert.Equal(t, []net.IP{net.ParseIP("8.8.8.8"), net.ParseIP("1.1.1.1")}, s.DNS)
+	}
+
+	for key, value := range map[string]string{
+		"ENV_CONFIG_ADDRESS": "10.0.0.256",
+		"ENV_CONFIG_SUBNET":  "10.0.0.0",
+		"ENV_CONFIG_PROXY":   "http_://proxy",
+	} {
+		os.Clearenv()
+		os.Setenv(key, value)
+
+		err := Process(&s, WithPrefix("env_config"))
+		v, ok := err.(*ParseError)
+		if !ok {
+			t.Fatalf("expected ParseError, got %T %v", err, err)
+		}
+		assert.Equal(t, key, v.KeyName)
+	}
+}
+
+func TestTimeLocation(t *testing.T) {
+	var s struct {
+		Plain    time.Time `time_format:"2006-01-02 15:04"`
+		NewYork  time.Time `time_format:"2006-01-02 15:04" location:"America/New_York"`
+		Explicit time.Time `location:"America/New_York"`
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_PLAIN", "2024-07-01 12:00")
+	os.Setenv("ENV_CONFIG_NEWYORK", "2024-07-01 12:00")
+	os.Setenv("ENV_CONFIG_EXPLICIT", "2024-07-01T12:00:00+02:00")
+
+	if assert.NoError(t, Process(&s, WithPrefix("env_config"))) {
+		_, offset := s.Plain.Zone()
+		assert.Equal(t, 0, offset)
+
+		_, offset = s.NewYork.Zone()
+		assert.Equal(t, -4*60*60, offset)
+		assert.Equal(t, "America/New_York", s.NewYork.Location().String())
+		assert.Equal(t, 4*time.Hour, s.NewYork.Sub(s.Plain))
+
+		_, offset = s.Explicit.Zone()
+		assert.Equal(t, 2*60*60, offset)
+	}
+
+	var invalid struct {
+		Start time.Time `location:"Mars/Olympus_Mons"`
+	}
+	err := Process(&invalid, WithPrefix("env_config"))
+	assert.ErrorContains(t, err, "field Start: unknown time zone Mars/Olympus_Mons")
+}
+
+func TestRequiredIf(t *testing.T) {
+	type spec struct {
+		TLSEnabled  bool
+		TLSCertPath string `required_if:"TLSEnabled=true"`
+		Mode        string
+		Token       string `required_if:"Mode=remote"`
+		Replicas    int
+		Leader      string `required_if:"Replicas=3"`
+	}
+
+	os.Clearenv()
+	assert.NoError(t, Process(&spec{}, WithPrefix("env_config")))
+
+	os.Setenv("ENV_CONFIG_TLSENABLED", "true")
+	err := Process(&spec{}, WithPrefix("env_config"))
+	assert.EqualError(t, err, "required key ENV_CONFIG_TLSCERTPATH missing value")
+
+	os.Setenv("ENV_CONFIG_TLSCERTPATH", "/etc/tls/cert.pem")
+	os.Setenv("ENV_CONFIG_MODE", "remote")
+	err = Process(&spec{}, WithPrefix("env_config"))
+	assert.EqualError(t, err, "required key ENV_CONFIG_TOKEN missing value")
+
+	os.Setenv("ENV_CONFIG_MODE", "local")
+	os.Setenv("ENV_CONFIG_REPLICAS", "3")
+	err = Process(&spec{}, WithPrefix("env_config"))
+	assert.EqualError(t, err, "required key ENV_CONFIG_LEADER missing value")
+
+	os.Setenv("ENV_CONFIG_LEADER", "node-1")
+	var s spec
+	if assert.NoError(t, Process(&s, WithPrefix("env_config"))) {
+		assert.Equal(t, "/etc/tls/cert.pem", s.TLSCertPath)
+		assert.Equal(t, "node-1", s.Leader)
+	}
+
+	// invalid conditions are reported even when the field is set
+	os.Setenv("ENV_CONFIG_TOKEN", "secret")
+	var unknown struct {
+		Token string `required_if:"Mode=remote"`
+	}
+	err = Process(&unknown, WithPrefix("env_config"))
+	assert.ErrorIs(t, err, ErrInvalidSpecification)
+	assert.ErrorContains(t, err, "required_if of field Token: unknown field Mode")
+
+	var malformed struct {
+		Mode  string
+		Token string `required_if:"Mode"`
+	}
+	err = Process(&malformed, WithPrefix("env_config"))
+	assert.ErrorIs(t, err, ErrInvalidSpecification)
+	assert.ErrorContains(t, err, `invalid required_if "Mode" of field Token`)
+}
+
+func TestDefaultExpansion(t *testing.T) {
+	var s struct {
+		ConfigPath string `default:"${HOME}/config"`
+		Cache      string `default:"$HOME/cache/$APP"`
+		Literal    string
+	}
+
+	os.Clearenv()
+	os.Setenv("HOME", "/home/app")
+	os.Setenv("ENV_CONFIG_LITERAL", "${HOME}/literal")
+
+	if assert.NoError(t, Process(&s, WithPrefix("env_config"))) {
+		assert.Equal(t, "${HOME}/config", s.ConfigPath)
+		assert.Equal(t, "$HOME/cache/$APP", s.Cache)
+	}
+
+	if assert.NoError(t, Process(&s, WithPrefix("env_config"), WithDefaultExpansion())) {
+		assert.Equal(t, "/home/app/config", s.ConfigPath)
+		assert.Equal(t, "/home/app/cache/", s.Cache)
+		assert.Equal(t, "${HOME}/literal", s.Literal)
+	}
+
+	lookuper := MapLookuper{"HOME": "/srv"}
+	if assert.NoError(t, Process(&s, WithPrefix("env_config"), WithDefaultExpansion(), WithLookuper(lookuper))) {
+		assert.Equal(t, "/srv/config", s.ConfigPath)
+	}
+}
+
+func TestSlicesOfSpecialTypes(t *testing.T) {
+	var s struct {
+		RetryBackoffs []time.Duration      `delimiter:";"`
+		Windows       []time.Time          `time_format:"2006-01-02 15:04" location:"America/New_York" delimiter:";"`
+		Deadlines     map[string]time.Time `time_format:"2006-01-02"`
+		Timeouts      []int64              `as:"duration" unit:"ms"`
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_RETRYBACKOFFS", "100ms;1s;1m")
+	os.Setenv("ENV_CONFIG_WINDOWS", "2024-07-01 09:00;2024-07-01 17:00")
+	os.Setenv("ENV_CONFIG_DEADLINES", "alpha:2024-01-31,beta:2024-02-29")
+	os.Setenv("ENV_CONFIG_TIMEOUTS", "1s,250ms")
+
+	if !assert.NoError(t, Process(&s, WithPrefix("env_config"))) {
+		return
+	}
+
+	assert.Equal(t, []time.Duration{100 * time.Millisecond, time.Second, time.Minute}, s.RetryBackoffs)
+	if assert.Len(t, s.Windows, 2) {
+		for _, w := range s.Windows {
+			assert.Equal(t, "America/New_York", w.Location().String())
+		}
+		assert.Equal(t, 8*time.Hour, s.Windows[1].Sub(s.Windows[0]))
+		assert.Equal(t, 13, s.Windows[0].UTC().Hour())
+	}
+	assert.Equal(t, map[string]time.Time{
+		"alpha": time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC),
+		"beta":  time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+	}, s.Deadlines)
+	assert.Equal(t, []int64{1000, 250}, s.Timeouts)
+}
+
+func TestBoolValues(t *testing.T) {
+	var s struct {
+		Debug   bool
+		Verbose bool
+		Cache   *bool
+		Flags   []bool
+		Legacy  bool `default:"t"`
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_DEBUG", "Yes")
+	os.Setenv("ENV_CONFIG_VERBOSE", "off")
+	os.Setenv("ENV_CONFIG_CACHE", "ON")
+	os.Setenv("ENV_CONFIG_FLAGS", "yes,no,true")
+
+	err := Process(&s, WithPrefix("env_config"))
+	assert.IsType(t, &ParseError{}, err)
+
+	opt := WithBoolValues([]string{"yes", "on"}, []string{"no", "off"})
+	if assert.NoError(t, Process(&s, WithPrefix("env_config"), opt)) {
+		assert.True(t, s.Debug)
+		assert.False(t, s.Verbose)
+		if assert.NotNil(t, s.Cache) {
+			assert.True(t, *s.Cache)
+		}
+		assert.Equal(t, []bool{true, false, true}, s.Flags)
+		assert.True(t, s.Legacy)
+	}
+
+	os.Setenv("ENV_CONFIG_DEBUG", "maybe")
+	err = Process(&s, WithPrefix("env_config"), opt)
+	v, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("expected ParseError, got %T %v", err, err)
+	}
+	assert.Equal(t, "Debug", v.FieldName)
+
+	var tags struct {
+		Host string `required:"yes"`
+	}
+	os.Clearenv()
+	assert.NoError(t, Process(&tags, WithPrefix("env_config"), opt))
+}
+
+func TestComplexNumbers(t *testing.T) {
+	var s struct {
+		Gain   complex128
+		Offset complex64
+		Taps   []complex128
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_GAIN", "1.5+2i")
+	os.Setenv("ENV_CONFIG_OFFSET", "(-0.5-1i)")
+	os.Setenv("ENV_CONFIG_TAPS", "1,2i,3+4i")
+
+	if assert.NoError(t, Process(&s, WithPrefix("env_config"))) {
+		assert.Equal(t, complex(1.5, 2), s.Gain)
+		assert.Equal(t, complex64(complex(-0.5, -1)), s.Offset)
+		assert.Equal(t, []complex128{1, 2i, 3 + 4i}, s.Taps)
+	}
+
+	os.Setenv("ENV_CONFIG_GAIN", "1.5+j")
+	err := Process(&s, WithPrefix("env_config"))
+	v, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("expected ParseError, got %T %v", err, err)
+	}
+	assert.Equal(t, "Gain", v.FieldName)
+}
+
+func TestRequiredByDefault(t *testing.T) {
+	type spec struct {
+		Host     string
+		Port     int    `default:"8080"`
+		Token    string `optional:"true"`
+		Debug    bool   `required:"false"`
+		Database struct {
+			Name string
+		}
+	}
+
+	os.Clearenv()
+	assert.NoError(t, Process(&spec{}, WithPrefix("env_config")))
+
+	err := Process(&spec{}, WithPrefix("env_config"), WithRequiredByDefault())
+	assert.EqualError(t, err, "required key ENV_CONFIG_HOST missing value")
+
+	os.Setenv("ENV_CONFIG_HOST", "localhost")
+	err = Process(&spec{}, WithPrefix("env_config"), WithRequiredByDefault())
+	assert.EqualError(t, err, "required key ENV_CONFIG_DATABASE_NAME missing value")
+
+	os.Setenv("ENV_CONFIG_DATABASE_NAME", "app")
+	var s spec
+	if assert.NoError(t, Process(&s, WithPrefix("env_config"), WithRequiredByDefault())) {
+		assert.Equal(t, 8080, s.Port)
+		assert.Equal(t, "", s.Token)
+	}
+}
+
+func TestStrictUnknownVars(t *testing.T) {
+	var s struct {
+		Host     string
+		Password string
+		Port     int `envconfig:"SERVICE_PORT"`
+	}
+
+	os.Clearenv()
+	os.Setenv("HOME", "/root")
+	os.Setenv("ENV_CONFIG_HOST", "localhost")
+	os.Setenv("ENV_CONFIG_PASSWORD_FILE", "testdata/token.txt")
+	os.Setenv("SERVICE_PORT", "8080")
+
+	assert.NoError(t, Process(&s, WithPrefix("env_config"), WithStrictUnknownVars()))
+
+	os.Setenv("ENV_CONFIG_HOTS", "localhost")
+	assert.NoError(t, Process(&s, WithPrefix("env_config")))
+	err := Process(&s, WithPrefix("env_config"), WithStrictUnknownVars())
+	assert.EqualError(t, err, "unknown environment variable ENV_CONFIG_HOTS")
+
+	os.Clearenv()
+	os.Setenv("HOST", "localhost")
+	os.Setenv("PATH", "/bin")
+	os.Setenv("SERVICE_PORTS", "8080")
+	assert.NoError(t, Process(&s, WithStrictUnknownVars()))
+
+	prefixes := []string{"service_"}
+	err = Process(&s, WithStrictUnknownVars(prefixes...))
+	assert.EqualError(t, err, "unknown environment variable SERVICE_PORTS")
+	assert.Equal(t, []string{"service_"}, prefixes)
+}
+
+func TestProcessContext(t *testing.T) {
+	var s struct {
+		Password string
+		Token    string `sources:"file:testdata/token.txt"`
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_PASSWORD_FILE", "testdata/token.txt")
+
+	if assert.NoError(t, ProcessContext(context.Background(), &s, WithPrefix("env_config"))) {
+		assert.Equal(t, "file", s.Password)
+		assert.Equal(t, "file", s.Token)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := ProcessContext(ctx, &s, WithPrefix("env_config"))
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.EqualError(t, err, "reading testdata/token.txt: context canceled")
+
+	os.Clearenv()
+	err = ProcessContext(ctx, &s, WithPrefix("env_config"))
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestPointerFields(t *testing.T) {
+	var s struct {
+		Names    *[]string
+		Weights  *map[string]int
+		Depth    **int
+		Deeper   ***string
+		Ptrs     []*int
+		Duration **time.Duration
+		Unset    **int
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_NAMES", "a,b")
+	os.Setenv("ENV_CONFIG_WEIGHTS", "a:1,b:2")
+	os.Setenv("ENV_CONFIG_DEPTH", "3")
+	os.Setenv("ENV_CONFIG_DEEPER", "x")
+	os.Setenv("ENV_CONFIG_PTRS", "1,2")
+	os.Setenv("ENV_CONFIG_DURATION", "1m")
+
+	if !assert.NoError(t, Process(&s, WithPrefix("env_config"))) {
+		return
+	}
+
+	if assert.NotNil(t, s.Names) {
+		assert.Equal(t, []string{"a", "b"}, *s.Names)
+	}
+	if assert.NotNil(t, s.Weights) {
+		assert.Equal(t, map[string]int{"a": 1, "b": 2}, *s.Weights)
+	}
+	if assert.NotNil(t, s.Depth) && assert.NotNil(t, *s.Depth) {
+		assert.Equal(t, 3, **s.Depth)
+	}
+	if assert.NotNil(t, s.Deeper) && assert.NotNil(t, *s.Deeper) && assert.NotNil(t, **s.Deeper) {
+		assert.Equal(t, "x", ***s.Deeper)
+	}
+	if assert.Len(t, s.Ptrs, 2) {
+		assert.Equal(t, 1, *s.Ptrs[0])
+		assert.Equal(t, 2, *s.Ptrs[1])
+	}
+	if assert.NotNil(t, s.Duration) && assert.NotNil(t, *s.Duration) {
+		assert.Equal(t, time.Minute, **s.Duration)
+	}
+	assert.Nil(t, s.Unset)
+
+	os.Setenv("ENV_CONFIG_DEPTH", "three")
+	err := Process(&s, WithPrefix("env_config"))
+	v, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("expected ParseError, got %T %v", err, err)
+	}
+	assert.Equal(t, "Depth", v.FieldName)
+}
+
+func TestEncodedBytesErrors(t *testing.T) {
+	var s struct {
+		Salt []byte `encoding:"base64"`
+		Key  []byte `encoding:"hex"`
+		Blob []byte `encoding:"base32"`
+	}
+
+	for key, value := range map[string]string{
+		"ENV_CONFIG_SALT": "not base64!",
+		"ENV_CONFIG_KEY":  "xyz",
+		"ENV_CONFIG_BLOB": "abc",
+	} {
+		os.Clearenv()
+		os.Setenv(key, value)
+
+		err := Process(&s, WithPrefix("env_config"))
+		v, ok := err.(*ParseError)
+		if !ok {
+			t.Fatalf("expected ParseError, got %T %v", err, err)
+		}
+		assert.Equal(t, key, v.KeyName)
+	}
+}
+
+func TestFieldTransformer(t *testing.T) {
+	var s struct {
+		LogLevel string `split_words:"true"`
+		DataDir  string `split_words:"true" default:"~/data"`
+		Token    string `pipe:"upper"`
+		Port     int
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_LOG_LEVEL", "  DEBUG ")
+	os.Setenv("ENV_CONFIG_TOKEN", " abc ")
+	os.Setenv("ENV_CONFIG_PORT", "8080")
+
+	var seen []string
+	lower := WithFieldTransformer(func(key, value string) string {
+		seen = append(seen, key+"="+value)
+		if key == "ENV_CONFIG_LOG_LEVEL" {
+			return strings.ToLower(value)
+		}
+		return value
+	})
+	home := WithFieldTransformer(func(key, value string) string {
+		if strings.HasPrefix(value, "~/") {
+			return "/home/app" + value[1:]
+		}
+		return value
+	})
+
+	if assert.NoError(t, Process(&s, WithPrefix("env_config"), lower, home)) {
+		assert.Equal(t, "debug", s.LogLevel)
+		assert.Equal(t, "/home/app/data", s.DataDir)
+		assert.Equal(t, "ABC", s.Token)
+		assert.Equal(t, 8080, s.Port)
+	}
+	assert.Equal(t, []string{
+		"ENV_CONFIG_LOG_LEVEL=DEBUG",
+		"ENV_CONFIG_DATA_DIR=~/data",
+		"ENV_CONFIG_TOKEN=abc",
+		"ENV_CONFIG_PORT=8080",
+	}, seen)
+}
+
+func TestFieldTransformerDefaultFrom(t *testing.T) {
+	var s struct {
+		DataDir  string `split_words:"true" default:"~/data"`
+		CacheDir string `split_words:"true" default_from:"DataDir"`
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_CACHE_DIR", "~/cache")
+
+	home := WithFieldTransformer(func(key, value string) string {
+		if strings.HasPrefix(value, "~/") {
+			return "/home/app" + value[1:]
+		}
+		return value
+	})
+
+	if assert.NoError(t, Process(&s, WithPrefix("env_config"), home)) {
+		assert.Equal(t, "/home/app/data", s.DataDir)
+		assert.Equal(t, "/home/app/cache", s.CacheDir)
+	}
+}
+
+func TestBigNumbers(t *testing.T) {
+	var s struct {
+		Balance  *big.Int
+		Mask     big.Int
+		Rate     *big.Float
+		Accounts []*big.Int
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_BALANCE", "123456789012345678901234567890")
+	os.Setenv("ENV_CONFIG_MASK", "0xff")
+	os.Setenv("ENV_CONFIG_RATE", "0.000000000000000000012345")
+	os.Setenv("ENV_CONFIG_ACCOUNTS", "1,0b101")
+
+	if assert.NoError(t, Process(&s, WithPrefix("env_config"))) {
+		if assert.NotNil(t, s.Balance) {
+			assert.Equal(t, "123456789012345678901234567890", s.Balance.String())
+		}
+		assert.Equal(t, int64(255), s.Mask.Int64())
+		if assert.NotNil(t, s.Rate) {
+			assert.Equal(t, "1.2345e-20", s.Rate.Text('g', 5))
+		}
+		if assert.Len(t, s.Accounts, 2) {
+			assert.Equal(t, int64(5), s.Accounts[1].Int64())
+		}
+	}
+
+	for key, value := range map[string]string{
+		"ENV_CONFIG_BALANCE": "12.5",
+		"ENV_CONFIG_RATE":    "one",
+	} {
+		os.Clearenv()
+		os.Setenv(key, value)
+
+		err := Process(&s, WithPrefix("env_config"))
+		v, ok := err.(*ParseError)
+		if !ok {
+			t.Fatalf("expected ParseError, got %T %v", err, err)
+		}
+		assert.Equal(t, key, v.KeyName)
+	}
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, Usagef(&s, buf, "{{range .}}{{usage_type .}}\n{{end}}"))
+	assert.Equal(t, "Arbitrary-precision Integer\nArbitrary-precision Integer\nArbitrary-precision Float\nComma-separated list of Arbitrary-precision Integer\n", buf.String())
+}
+
+func TestJSONFields(t *testing.T) {
+	type Limits struct {
+		Burst int      `json:"burst"`
+		Paths []string `json:"paths"`
+	}
+	type Routing struct {
+		Routes *Limits `json:"true"`
+	}
+	type Weighting struct {
+		Weights map[string][]int `json:"true" default:"{\"a\":[1]}"`
+	}
+
+	var s struct {
+		Limits  Limits `json:"true"`
+		Routing Routing
+		Weighting
+		Name string
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_LIMITS", `{"burst": 10, "paths": ["/a", "/b"]}`)
+	os.Setenv("ENV_CONFIG_ROUTING_ROUTES", `{"burst": 2}`)
+	os.Setenv("ENV_CONFIG_NAME", "api")
+
+	if assert.NoError(t, Process(&s, WithPrefix("env_config"))) {
+		assert.Equal(t, Limits{Burst: 10, Paths: []string{"/a", "/b"}}, s.Limits)
+		if assert.NotNil(t, s.Routing.Routes) {
+			assert.Equal(t, 2, s.Routing.Routes.Burst)
+		}
+		assert.Equal(t, map[string][]int{"a": {1}}, s.Weights)
+		assert.Equal(t, "api", s.Name)
+	}
+
+	os.Setenv("ENV_CONFIG_WEIGHTS", `{"a": [1, 2], "b": []}`)
+	if assert.NoError(t, Process(&s, WithPrefix("env_config"))) {
+		assert.Equal(t, map[string][]int{"a": {1, 2}, "b": {}}, s.Weights)
+	}
+
+	// the struct is a single variable, its fields are not configured individually
+	infos, err := Describe(&s, WithPrefix("env_config"))
+	if assert.NoError(t, err) {
+		keys := make([]string, 0, len(infos))
+		for _, info := range infos {
+			keys = append(keys, info.Key)
+		}
+		assert.Equal(t, []string{"ENV_CONFIG_LIMITS", "ENV_CONFIG_ROUTING_ROUTES", "ENV_CONFIG_WEIGHTS", "ENV_CONFIG_NAME"}, keys)
+	}
+
+	os.Setenv("ENV_CONFIG_LIMITS", `{"burst": "ten"}`)
+	err = Process(&s, WithPrefix("env_config"))
+	v, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("expected ParseError, got %T %v", err, err)
+	}
+	assert.Equal(t, "ENV_CONFIG_LIMITS", v.KeyName)
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, Usagef(&s, buf, "{{range .}}{{usage_type .}}\n{{end}}"))
+	assert.Equal(t, "JSON\nJSON\nJSON\nString\n", buf.String())
+}
+
+func TestEmptyAsUnset(t *testing.T) {
+	type spec struct {
+		Host  string `default:"localhost"`
+		Port  int    `default:"8080"`
+		Token string `required:"true"`
+		Path  string `default:"/" empty_as_unset:"false"`
+		Mode  string `default:"fast" empty_as_unset:"true"`
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_HOST", "")
+	os.Setenv("ENV_CONFIG_PORT", " ")
+	os.Setenv("ENV_CONFIG_TOKEN", "t")
+	os.Setenv("ENV_CONFIG_PATH", "")
+	os.Setenv("ENV_CONFIG_MODE", "")
+
+	var s spec
+	if assert.NoError(t, Process(&s, WithPrefix("env_config"), WithEmptyAsUnset())) {
+		assert.Equal(t, "localhost", s.Host)
+		assert.Equal(t, 8080, s.Port)
+		assert.Equal(t, "", s.Path)
+		assert.Equal(t, "fast", s.Mode)
+	}
+
+	// empty values are assigned by default
+	s = spec{}
+	os.Unsetenv("ENV_CONFIG_PORT")
+	if assert.NoError(t, Process(&s, WithPrefix("env_config"))) {
+		assert.Equal(t, "", s.Host)
+		assert.Equal(t, 8080, s.Port)
+		assert.Equal(t, "fast", s.Mode)
+	}
+
+	os.Setenv("ENV_CONFIG_TOKEN", "")
+	err := Process(&s, WithPrefix("env_config"), WithEmptyAsUnset())
+	assert.EqualError(t, err, "required key ENV_CONFIG_TOKEN missing value")
+}
+
+func TestMapSliceValues(t *testing.T) {
+	var s struct {
+		Weights  map[string][]int
+		Hosts    map[string][]string        `value_delimiter:";"`
+		Timeouts map[string][]time.Duration `delimiter:" " map_separator:"="`
+		Levels   map[int]*[]uint
+		Names    map[string][]string `keep_empty:"true"`
+		Secrets  map[string][]byte
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_WEIGHTS", "a:1|2,b:3|4,c:5")
+	os.Setenv("ENV_CONFIG_HOSTS", "eu:a.example.com;b.example.com,us:c.example.com")
+	os.Setenv("ENV_CONFIG_TIMEOUTS", "read=1s|2s write=5s")
+	os.Setenv("ENV_CONFIG_LEVELS", "1:7|8")
+	os.Setenv("ENV_CONFIG_NAMES", "x:a||b")
+	os.Setenv("ENV_CONFIG_SECRETS", "k:a|b")
+
+	if assert.NoError(t, Process(&s, WithPrefix("env_config"))) {
+		assert.Equal(t, map[string][]int{"a": {1, 2}, "b": {3, 4}, "c": {5}}, s.Weights)
+		assert.Equal(t, map[string][]string{"eu": {"a.example.com", "b.example.com"}, "us": {"c.example.com"}}, s.Hosts)
+		assert.Equal(t, map[string][]time.Duration{"read": {time.Second, 2 * time.Second}, "write": {5 * time.Second}}, s.Timeouts)
+		if assert.Contains(t, s.Levels, 1) {
+			assert.Equal(t, []uint{7, 8}, *s.Levels[1])
+		}
+		assert.Equal(t, map[string][]string{"x": {"a", "", "b"}}, s.Names)
+		// bytes are not a list
+		assert.Equal(t, map[string][]byte{"k": []byte("a|b")}, s.Secrets)
+	}
+
+	os.Setenv("ENV_CONFIG_WEIGHTS", "a:1|x")
+	err := Process(&s, WithPrefix("env_config"))
+	v, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("expected ParseError, got %T %v", err, err)
+	}
+	assert.Equal(t, "ENV_CONFIG_WEIGHTS", v.KeyName)
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, Usagef(&s, buf, "{{range .}}{{usage_type .}}\n{{end}}"))
+	assert.Equal(t, strings.Join([]string{
+		"Comma-separated list of String:|-separated list of Integer pairs",
+		"Comma-separated list of String:Semicolon-separated list of String pairs",
+		"Space-separated list of String=|-separated list of Duration pairs",
+		"Comma-separated list of Integer:|-separated list of Unsigned Integer pairs",
+		"Comma-separated list of String:|-separated list of String pairs",
+		"Comma-separated list of String:String pairs",
+		"",
+	}, "\n"), buf.String())
+}
+
+func TestParseInto(t *testing.T) {
+	var (
+		port     int
+		timeout  *time.Duration
+		hosts    []string
+		labels   map[string]int
+		addr     net.IP
+		cidr     *net.IPNet
+		custom   bracketed
+		inner    setterStruct
+		verbose  bool
+		location *url.URL
+	)
+
+	assert.NoError(t, ParseInto("8080", &port))
+	assert.Equal(t, 8080, port)
+
+	assert.NoError(t, ParseInto("1m", &timeout))
+	if assert.NotNil(t, timeout) {
+		assert.Equal(t, time.Minute, *timeout)
+	}
+
+	assert.NoError(t, ParseInto("a;b", &hosts, WithDefaultDelimiter(";")))
+	assert.Equal(t, []string{"a", "b"}, hosts)
+
+	assert.NoError(t, ParseInto("x:1,y:2", &labels))
+	assert.Equal(t, map[string]int{"x": 1, "y": 2}, labels)
+
+	assert.NoError(t, ParseInto("10.0.0.1", &addr))
+	assert.Equal(t, "10.0.0.1", addr.String())
+
+	assert.NoError(t, ParseInto("10.0.0.0/8", &cidr))
+	assert.Equal(t, "10.0.0.0/8", cidr.String())
+
+	assert.NoError(t, ParseInto("foo", &custom))
+	assert.Equal(t, bracketed("[foo]"), custom)
+
+	assert.NoError(t, ParseInto("bar", &inner))
+	assert.Equal(t, `setterstruct{"bar"}`, inner.Inner)
+
+	assert.NoError(t, ParseInto("yes", &verbose, WithBoolValues([]string{"yes"}, []string{"no"})))
+	assert.True(t, verbose)
+
+	assert.NoError(t, ParseInto("https://example.com/path", &location))
+	assert.Equal(t, "example.com", location.Host)
+
+	assert.Error(t, ParseInto("eighty", &port))
+	assert.ErrorIs(t, ParseInto("1", port), ErrInvalidTarget)
+	assert.ErrorIs(t, ParseInto("1", (*int)(nil)), ErrInvalidTarget)
+	assert.ErrorIs(t, ParseInto("1", nil), ErrInvalidTarget)
+
+	var plain struct{ Port int }
+	assert.EqualError(t, ParseInto("80", &plain), "unsupported type struct { Port int }")
+	assert.Equal(t, 0, plain.Port)
+}
+
+func TestWithTrimCutset(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		opts     []Option
+		expected string
+	}{
+		{
+			name:     "quotes after spaces",
+			value:    "\"secret\"\n",
+			opts:     []Option{WithTrimCutset(`"'`)},
+			expected: "secret",
+		},
+		{
+			name:     "spaces inside quotes are kept",
+			value:    "' secret '",
+			opts:     []Option{WithTrimCutset(`'`)},
+			expected: " secret ",
+		},
+		{
+			name:     "cutset only",
+			value:    " \"secret\"\n",
+			opts:     []Option{WithoutTrimSpaces(), WithTrimCutset("\"\n")},
+			expected: " \"secret",
+		},
+		{
+			name:     "spaces only",
+			value:    " \"secret\"\n",
+			expected: "\"secret\"",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s struct {
+				Token string
+				Mode  string `default:"'fast'"`
+			}
+
+			os.Clearenv()
+			os.Setenv("ENV_CONFIG_TOKEN", tt.value)
+
+			if assert.NoError(t, Process(&s, append([]Option{WithPrefix("env_config")}, tt.opts...)...)) {
+				assert.Equal(t, tt.expected, s.Token)
+				// defaults are not trimmed
+				assert.Equal(t, "'fast'", s.Mode)
+			}
+		})
+	}
+}
+
+func TestProcessPrefix(t *testing.T) {
+	type Log struct {
+		Level  string `required:"true"`
+		Format string `default:"text"`
+	}
+
+	var s struct {
+		Log     Log
+		Login   string
+		Port    int    `required:"true"`
+		LogFile string `envconfig:"log_file"`
+	}
+	s.Port = 80
+	s.Login = "admin"
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_LOG_LEVEL", "debug")
+	os.Setenv("ENV_CONFIG_LOG_FILE", "/var/log/app")
+	os.Setenv("ENV_CONFIG_LOGIN", "root")
+
+	// the required port is neither read nor checked
+	if assert.NoError(t, ProcessPrefix(&s, "log", WithPrefix("env_config"))) {
+		assert.Equal(t, Log{Level: "debug", Format: "text"}, s.Log)
+		assert.Equal(t, "/var/log/app", s.LogFile)
+		assert.Equal(t, "admin", s.Login)
+		assert.Equal(t, 80, s.Port)
+	}
+
+	os.Setenv("ENV_CONFIG_LOG_LEVEL", "info")
+	os.Setenv("ENV_CONFIG_LOG_FILE", "")
+	err := ProcessPrefix(&s, "LOG_", WithPrefix("env_config"), WithKeyFilter(func(key string) bool {
+		return key != "ENV_CONFIG_LOG_FILE"
+	}))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "info", s.Log.Level)
+		assert.Equal(t, "/var/log/app", s.LogFile)
+	}
+
+	os.Unsetenv("ENV_CONFIG_LOG_LEVEL")
+	err = ProcessPrefix(&s, "log", WithPrefix("env_config"))
+	assert.EqualError(t, err, "required key ENV_CONFIG_LOG_LEVEL missing value")
+
+	// variables of the fields left alone are not unknown, those under the sub prefix are
+	os.Setenv("ENV_CONFIG_LOG_LEVEL", "info")
+	assert.NoError(t, ProcessPrefix(&s, "log", WithPrefix("env_config"), WithStrictUnknownVars()))
+	os.Setenv("ENV_CONFIG_LOG_COLOR", "true")
+	err = ProcessPrefix(&s, "log", WithPrefix("env_config"), WithStrictUnknownVars())
+	assert.EqualError(t, err, "unknown environment variable ENV_CONFIG_LOG_COLOR")
+}
+
+func TestSliceAndMapDefaults(t *testing.T) {
+	var s struct {
+		Hosts   []string          `default:"a,b,c"`
+		Ports   []int             `default:"80;443" delimiter:";"`
+		Labels  map[string]string `default:"k:v,k2:v2"`
+		Limits  map[string]int    `default:"cpu=2 mem=512" delimiter:" " map_separator:"="`
+		Weights map[string][]int  `default:"a:1|2,b:3"`
+		Empty   []string          `default:""`
+	}
+
+	os.Clearenv()
+	if assert.NoError(t, Process(&s, WithPrefix("env_config"))) {
+		assert.Len(t, s.Hosts, 3)
+		assert.Equal(t, []string{"a", "b", "c"}, s.Hosts)
+		assert.Equal(t, []int{80, 443}, s.Ports)
+		assert.Len(t, s.Labels, 2)
+		assert.Equal(t, map[string]string{"k": "v", "k2": "v2"}, s.Labels)
+		assert.Equal(t, map[string]int{"cpu": 2, "mem": 512}, s.Limits)
+		assert.Equal(t, map[string][]int{"a": {1, 2}, "b": {3}}, s.Weights)
+		assert.Empty(t, s.Empty)
+	}
+
+	// env values follow the same rules and replace the defaults as a whole
+	os.Setenv("ENV_CONFIG_HOSTS", "d")
+	os.Setenv("ENV_CONFIG_LIMITS", "cpu=4")
+	if assert.NoError(t, Process(&s, WithPrefix("env_config"))) {
+		assert.Equal(t, []string{"d"}, s.Hosts)
+		assert.Equal(t, map[string]int{"cpu": 4}, s.Limits)
+	}
+
+	// as does the option delimiter
+	os.Clearenv()
+	var o struct {
+		Hosts []string `default:"a|b"`
+	}
+	if assert.NoError(t, Process(&o, WithPrefix("env_config"), WithDefaultDelimiter("|"))) {
+		assert.Equal(t, []string{"a", "b"}, o.Hosts)
+	}
+}
+
+func TestUnitBytesAndPercent(t *testing.T) {
+	var s struct {
+		MaxMemory  int64   `unit:"bytes"`
+		CacheSize  uint64  `unit:"bytes"`
+		Buffer     int     `unit:"bytes"`
+		Limits     []int   `unit:"bytes"`
+		Ratio      float64 `unit:"bytes"`
+		SampleRate float64 `unit:"percent"`
+		Threshold  int     `unit:"percent"`
+		Plain      float32 `unit:"percent"`
+		Count      int
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_MAXMEMORY", "512MiB")
+	os.Setenv("ENV_CONFIG_CACHESIZE", "2 GB")
+	os.Setenv("ENV_CONFIG_BUFFER", "0x400")
+	os.Setenv("ENV_CONFIG_LIMITS", "1kib,1.5KiB,10b,7")
+	os.Setenv("ENV_CONFIG_RATIO", "0.5KB")
+	os.Setenv("ENV_CONFIG_SAMPLERATE", "5%")
+	os.Setenv("ENV_CONFIG_THRESHOLD", "80 %")
+	os.Setenv("ENV_CONFIG_PLAIN", "0.25")
+	os.Setenv("ENV_CONFIG_COUNT", "3")
+
+	if assert.NoError(t, Process(&s, WithPrefix("env_config"))) {
+		assert.Equal(t, int64(512<<20), s.MaxMemory)
+		assert.Equal(t, uint64(2e9), s.CacheSize)
+		assert.Equal(t, 1024, s.Buffer)
+		assert.Equal(t, []int{1024, 1536, 10, 7}, s.Limits)
+		assert.Equal(t, 500.0, s.Ratio)
+		assert.InDelta(t, 0.05, s.SampleRate, 1e-9)
+		assert.Equal(t, 80, s.Threshold)
+		assert.Equal(t, float32(0.25), s.Plain)
+		assert.Equal(t, 3, s.Count)
+	}
+
+	for key, value := range map[string]string{
+		"ENV_CONFIG_MAXMEMORY":  "512MiBs",
+		"ENV_CONFIG_CACHESIZE":  "20000PB",
+		"ENV_CONFIG_BUFFER":     "1.5B",
+		"ENV_CONFIG_SAMPLERATE": "five%",
+		"ENV_CONFIG_COUNT":      "3KiB",
+	} {
+		os.Clearenv()
+		os.Setenv(key, value)
+
+		err := Process(&s, WithPrefix("env_config"))
+		v, ok := err.(*ParseError)
+		if !ok {
+			t.Fatalf("expected ParseError, got %T %v", err, err)
+		}
+		assert.Equal(t, key, v.KeyName)
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_MAXMEMORY", "1TB")
+	err := Process(&s, WithPrefix("env_config"))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1e12), s.MaxMemory)
+
+	os.Setenv("ENV_CONFIG_MAXMEMORY", "1XB")
+	err = Process(&s, WithPrefix("env_config"))
+	assert.ErrorContains(t, err, `unknown byte size unit "XB"`)
+
+	os.Setenv("ENV_CONFIG_MAXMEMORY", "0.7KiB")
+	err = Process(&s, WithPrefix("env_config"))
+	assert.ErrorContains(t, err, `byte size "0.7KiB" is not a whole number of bytes`)
+
+	os.Setenv("ENV_CONFIG_MAXMEMORY", "-1KB")
+	err = Process(&s, WithPrefix("env_config"))
+	assert.ErrorContains(t, err, `byte size "-1KB" is negative`)
+
+	// float fields may hold a fraction of a byte
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_RATIO", "0.7KiB")
+	if assert.NoError(t, Process(&s, WithPrefix("env_config"))) {
+		assert.InDelta(t, 716.8, s.Ratio, 1e-9)
+	}
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, Usagef(&s, buf, "{{range .}}{{usage_type .}}\n{{end}}"))
+	assert.Equal(t, strings.Join([]string{
+		"Byte Size (e.g. 512MiB)",
+		"Byte Size (e.g. 512MiB)",
+		"Byte Size (e.g. 512MiB)",
+		"Comma-separated list of Byte Size (e.g. 512MiB)",
+		"Byte Size (e.g. 512MiB)",
+		"Percentage (e.g. 5%)",
+		"Percentage (e.g. 5%)",
+		"Percentage (e.g. 5%)",
+		"Integer",
+		"",
+	}, "\n"), buf.String())
+}
+
+func TestArrayFields(t *testing.T) {
+	var s struct {
+		Point   [3]int
+		Names   [2]string `delimiter:";"`
+		Key     [4]byte   `encoding:"hex"`
+		Windows map[string][2]time.Duration
+		Empty   [2]int
+		Flags   *[2]bool
+	}
+	s.Empty = [2]int{1, 2}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_POINT", "1,2,3")
+	os.Setenv("ENV_CONFIG_NAMES", "a;b")
+	os.Setenv("ENV_CONFIG_KEY", "deadbeef")
+	os.Setenv("ENV_CONFIG_WINDOWS", "day:8h|18h")
+	os.Setenv("ENV_CONFIG_EMPTY", "")
+	os.Setenv("ENV_CONFIG_FLAGS", "true,false")
+
+	if assert.NoError(t, Process(&s, WithPrefix("env_config"))) {
+		assert.Equal(t, [3]int{1, 2, 3}, s.Point)
+		assert.Equal(t, [2]string{"a", "b"}, s.Names)
+		assert.Equal(t, [4]byte{0xde, 0xad, 0xbe, 0xef}, s.Key)
+		assert.Equal(t, map[string][2]time.Duration{"day": {8 * time.Hour, 18 * time.Hour}}, s.Windows)
+		assert.Equal(t, [2]int{}, s.Empty)
+		if assert.NotNil(t, s.Flags) {
+			assert.Equal(t, [2]bool{true, false}, *s.Flags)
+		}
+	}
+
+	for key, value := range map[string]string{
+		"ENV_CONFIG_POINT": "1,2",
+		"ENV_CONFIG_NAMES": "a;b;c",
+		"ENV_CONFIG_KEY":   "dead",
+	} {
+		os.Clearenv()
+		os.Setenv(key, value)
+
+		err := Process(&s, WithPrefix("env_config"))
+		v, ok := err.(*ParseError)
+		if !ok {
+			t.Fatalf("expected ParseError, got %T %v", err, err)
+		}
+		assert.Equal(t, key, v.KeyName)
+		assert.ErrorContains(t, v.Err, "expected")
+	}
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, Usagef(&s, buf, "{{range .}}{{usage_type .}}\n{{end}}"))
+	assert.Equal(t, strings.Join([]string{
+		"Comma-separated list of 3 Integer",
+		"Semicolon-separated list of 2 String",
+		"Hex-encoded String of 4 bytes",
+		"Comma-separated list of String:|-separated list of Duration pairs",
+		"Comma-separated list of 2 Integer",
+		"Comma-separated list of 2 True or False",
+		"",
+	}, "\n"), buf.String())
+}
+
+func TestWithLazyStructPointers(t *testing.T) {
+	type TLS struct {
+		Cert string `required:"true"`
+		Key  string
+	}
+	type Cache struct {
+		Size int `default:"10"`
+		TLS  *TLS
+	}
+
+	type spec struct {
+		Name  string
+		Cache *Cache
+		TLS   *TLS
+		Proxy *Cache
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_NAME", "api")
+	os.Setenv("ENV_CONFIG_CACHE_TLS_CERT", "cert.pem")
+
+	var s spec
+	s.Proxy = &Cache{}
+	if assert.NoError(t, Process(&s, WithPrefix("env_config"), WithLazyStructPointers())) {
+		assert.Equal(t, "api", s.Name)
+		if assert.NotNil(t, s.Cache) && assert.NotNil(t, s.Cache.TLS) {
+			assert.Equal(t, 10, s.Cache.Size)
+			assert.Equal(t, "cert.pem", s.Cache.TLS.Cert)
+		}
+		// required fields of the unset struct are not checked
+		assert.Nil(t, s.TLS)
+		// allocated before processing, so not lazy
+		if assert.NotNil(t, s.Proxy) {
+			assert.Equal(t, 10, s.Proxy.Size)
+			assert.Nil(t, s.Proxy.TLS)
+		}
+	}
+
+	// only defaults set
+	os.Unsetenv("ENV_CONFIG_CACHE_TLS_CERT")
+	s = spec{}
+	if assert.NoError(t, Process(&s, WithPrefix("env_config"), WithLazyStructPointers())) {
+		assert.Nil(t, s.Cache)
+	}
+
+	os.Setenv("ENV_CONFIG_CACHE_SIZE", "20")
+	s = spec{}
+	if assert.NoError(t, Process(&s, WithPrefix("env_config"), WithLazyStructPointers())) {
+		if assert.NotNil(t, s.Cache) {
+			assert.Equal(t, 20, s.Cache.Size)
+			assert.Nil(t, s.Cache.TLS)
+		}
+	}
+
+	os.Setenv("ENV_CONFIG_TLS_KEY", "key.pem")
+	err := Process(&spec{}, WithPrefix("env_config"), WithLazyStructPointers())
+	assert.EqualError(t, err, "required key ENV_CONFIG_TLS_CERT missing value")
+
+	// allocated by default
+	err = Process(&spec{}, WithPrefix("env_config"))
+	assert.EqualError(t, err, "required key ENV_CONFIG_CACHE_TLS_CERT missing value")
+}
+
+func TestWithErrorOnUnsupportedType(t *testing.T) {
+	type Nested struct {
+		Done chan struct{}
+	}
+	type spec struct {
+		Name    string
+		Ports   []int
+		Handler func()
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_NAME", "api")
+	os.Setenv("ENV_CONFIG_HANDLER", "noop")
+
+	var s spec
+	if assert.NoError(t, Process(&s, WithPrefix("env_config"))) {
+		assert.Equal(t, "api", s.Name)
+		assert.Nil(t, s.Handler)
+	}
+
+	err := Process(&spec{}, WithPrefix("env_config"), WithErrorOnUnsupportedType())
+	assert.EqualError(t, err, "field Handler: unsupported type func()")
+
+	var nested struct {
+		Nested Nested
+	}
+	err = Process(&nested, WithPrefix("env_config"), WithErrorOnUnsupportedType())
+	assert.EqualError(t, err, "field Done: unsupported type chan struct {}")
+
+	var elems struct {
+		Streams map[string][]chan int
+	}
+	err = Process(&elems, WithPrefix("env_config"), WithErrorOnUnsupportedType())
+	assert.EqualError(t, err, "field Streams: unsupported type map[string][]chan int")
+
+	var supported struct {
+		Addr    net.IPNet
+		Timeout time.Duration
+		Labels  map[string][]string
+		Matrix  [2][]float64
+		Any     interface{}
+		Ignored func() `ignored:"true"`
+	}
+	assert.NoError(t, Process(&supported, WithPrefix("env_config"), WithErrorOnUnsupportedType()))
+}
+
+func TestWithCSVSlices(t *testing.T) {
+	type spec struct {
+		Names  []string
+		Paths  []string `delimiter:";"`
+		Pair   [2]string
+		Ports  []int
+		Wide   []string `delimiter:"::"`
+		Labels map[string][]string
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_NAMES", `"Doe, John",Jane,"say ""hi"""`)
+	os.Setenv("ENV_CONFIG_PATHS", `/tmp;"C:\Program Files;x86"`)
+	os.Setenv("ENV_CONFIG_PAIR", `"a,b",c`)
+	os.Setenv("ENV_CONFIG_PORTS", `80,"443"`)
+	os.Setenv("ENV_CONFIG_LABELS", `team:"a|b"|c`)
+
+	var s spec
+	if assert.NoError(t, Process(&s, WithPrefix("env_config"), WithCSVSlices())) {
+		assert.Equal(t, []string{"Doe, John", "Jane", `say "hi"`}, s.Names)
+		assert.Equal(t, []string{"/tmp", `C:\Program Files;x86`}, s.Paths)
+		assert.Equal(t, [2]string{"a,b", "c"}, s.Pair)
+		assert.Equal(t, []int{80, 443}, s.Ports)
+		assert.Equal(t, map[string][]string{"team": {"a|b", "c"}}, s.Labels)
+	}
+
+	// split on every delimiter by default
+	var names struct {
+		Names []string
+	}
+	if assert.NoError(t, Process(&names, WithPrefix("env_config"))) {
+		assert.Equal(t, []string{`"Doe`, ` John"`, "Jane", `"say ""hi"""`}, names.Names)
+	}
+
+	os.Setenv("ENV_CONFIG_NAMES", `"Doe, John,Jane`)
+	err := Process(&spec{}, WithPrefix("env_config"), WithCSVSlices())
+	v, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("expected ParseError, got %T %v", err, err)
+	}
+	assert.Equal(t, "Names", v.FieldName)
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_WIDE", "a::b")
+	err = Process(&spec{}, WithPrefix("env_config"), WithCSVSlices())
+	assert.ErrorContains(t, err, `csv slices require a single character delimiter, got "::"`)
+}
+
+// hexColor decodes itself from text like #ff8800, its default must not be parsed as a plain slice of bytes
+type hexColor []byte
+
+func (c *hexColor) UnmarshalText(text []byte) error {
+	b, err := hex.DecodeString(strings.TrimPrefix(string(text), "#"))
+	if err != nil {
+		return err
+	}
+	*c = b
+	return nil
+}
+
+func (c hexColor) MarshalText() ([]byte, error) {
+	return []byte("#" + hex.EncodeToString(c)), nil
+}
+
+func TestTextUnmarshalerDefault(t *testing.T) {
+	type spec struct {
+		Background hexColor    `default:"#FF8800"`
+		Foreground *hexColor   `default:"#000000"`
+		Started    time.Time   `default:"2024-01-02T03:04:05Z"`
+		Palette    []hexColor  `default:"#ffffff,#000000"`
+		Accent     hexColor    `default:"not hex"`
+		Ignored    interface{} `ignored:"true"`
+	}
+
+	os.Clearenv()
+
+	var s spec
+	err := Process(&s, WithPrefix("env_config"))
+	v, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("expected ParseError, got %T %v", err, err)
+	}
+	assert.Equal(t, "Accent", v.FieldName)
+
+	os.Setenv("ENV_CONFIG_ACCENT", "#00ff00")
+	s = spec{}
+	if assert.NoError(t, Process(&s, WithPrefix("env_config"))) {
+		assert.Equal(t, hexColor{0xff, 0x88, 0x00}, s.Background)
+		if assert.NotNil(t, s.Foreground) {
+			assert.Equal(t, hexColor{0, 0, 0}, *s.Foreground)
+		}
+		assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), s.Started)
+		assert.Equal(t, []hexColor{{0xff, 0xff, 0xff}, {0, 0, 0}}, s.Palette)
+		assert.Equal(t, hexColor{0, 0xff, 0}, s.Accent)
+	}
+}
@@ -0,0 +1,50 @@
+//go:build unix
+
+package envconfig
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProcessContextBlockingRead(t *testing.T) {
+	// opening a fifo for reading blocks until a writer shows up
+	fifo := filepath.Join(t.TempDir(), "password")
+	if err := syscall.Mkfifo(fifo, 0o600); err != nil {
+		t.Skipf("mkfifo: %v", err)
+	}
+	t.Cleanup(func() {
+		// release the abandoned read
+		if w, err := os.OpenFile(fifo, os.O_WRONLY|syscall.O_NONBLOCK, 0); err == nil {
+			w.Close()
+		}
+	})
+
+	var s struct {
+		Password string
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_PASSWORD_FILE", fifo)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	done := make(chan error, 1)
+	go func() {
+		done <- ProcessContext(ctx, &s, WithPrefix("env_config"))
+	}()
+
+	select {
+	case err := <-done:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(5 * time.Second):
+		t.Fatal("ProcessContext did not return once the context was cancelled")
+	}
+}
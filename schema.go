@@ -0,0 +1,225 @@
+package envconfig
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// jsonSchemaDraft is the JSON Schema version emitted by JSONSchema
+const jsonSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+type (
+	jsonSchema struct {
+		Schema               string                         `json:"$schema"`
+		Type                 string                         `json:"type"`
+		Properties           map[string]*jsonSchemaProperty `json:"properties"`
+		Required             []string                       `json:"required,omitempty"`
+		AdditionalProperties bool                           `json:"additionalProperties"`
+	}
+
+	jsonSchemaProperty struct {
+		Type        any             `json:"type"`
+		Description string          `json:"description,omitempty"`
+		Default     json.RawMessage `json:"default,omitempty"`
+		Enum        []any           `json:"enum,omitempty"`
+		Minimum     any             `json:"minimum,omitempty"`
+		Maximum     any             `json:"maximum,omitempty"`
+	}
+)
+
+// JSONSchema returns a JSON Schema describing the environment variables of the specification.
+// Every variable is a property of a single object keyed by its name.
+func JSONSchema(spec any, optsValues ...Option) ([]byte, error) {
+	opts := defaultOptions().apply(optsValues...).forSpec(spec)
+
+	vars, err := gatherInfo(spec, opts)
+	if err != nil {
+		return nil, err
+	}
+	vars = filterVars(vars, opts)
+
+	schema := jsonSchema{
+		Schema:     jsonSchemaDraft,
+		Type:       "object",
+		Properties: make(map[string]*jsonSchemaProperty, len(vars)),
+		// an environment holds plenty of unrelated variables
+		AdditionalProperties: true,
+	}
+
+	for _, v := range vars {
+		typ := toJSONSchemaType(v.field.Type())
+		property := &jsonSchemaProperty{
+			Type:        typ,
+			Description: v.fieldType.Tag.Get("desc"),
+		}
+
+		if names, ok := v.fieldType.Tag.Lookup(TagNames); ok {
+			// the parser accepts the names as well as their numbers
+			var numbers []any
+			for _, item := range strings.Split(names, ",") {
+				name, number, _ := strings.Cut(item, "=")
+				property.Enum = append(property.Enum, strings.TrimSpace(name))
+				if n, err := strconv.ParseInt(strings.TrimSpace(number), 0, 64); err == nil {
+					numbers = append(numbers, n)
+				}
+			}
+			property.Enum = append(property.Enum, numbers...)
+			property.Type = []string{"string", "integer"}
+		}
+
+		// the default of a secret field is left out rather than published
+		if def, ok := v.fieldType.Tag.Lookup(TagDefault); ok && !v.isSecret() {
+			property.Default = jsonSchemaDefault(v, def, typ)
+		}
+
+		// with oneof_fold the values are matched in any case, which an enum cannot express
+		if allowed := v.oneOf(); allowed != nil && typ == "string" && !isTrue(v.fieldType.Tag.Get(TagOneOfFold)) {
+			t := v.field.Type()
+			for t.Kind() == reflect.Ptr {
+				t = t.Elem()
+			}
+			if t.Kind() == reflect.String {
+				for _, value := range allowed {
+					property.Enum = append(property.Enum, value)
+				}
+			}
+		}
+
+		if typ == "integer" || typ == "number" {
+			if isUnsigned(v.field.Type()) {
+				property.Minimum = 0
+			}
+			if bound := jsonSchemaBound(v, TagMin); bound != nil {
+				property.Minimum = bound
+			}
+			if bound := jsonSchemaBound(v, TagMax); bound != nil {
+				property.Maximum = bound
+			}
+		}
+
+		schema.Properties[v.key] = property
+
+		if v.isRequired() {
+			schema.Required = append(schema.Required, v.key)
+		}
+	}
+
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// jsonSchemaDefault converts the default tag into a JSON value of the schema type.
+// Defaults that do not parse into the field are left out.
+func jsonSchemaDefault(v *variable, def, typ string) json.RawMessage {
+	var value any = def
+	if _, ok := v.fieldType.Tag.Lookup(TagNames); ok {
+		// a number of a names field stays a number
+		if n, err := strconv.ParseInt(def, 0, 64); err == nil {
+			value = n
+		}
+		typ = "string"
+	}
+
+	switch typ {
+	case "integer", "number", "boolean":
+		field := reflect.New(v.field.Type()).Elem()
+		if err := processField(def, field, v); err != nil {
+			return nil
+		}
+		for field.Kind() == reflect.Ptr {
+			field = field.Elem()
+		}
+		switch field.Kind() {
+		case reflect.Bool:
+			value = field.Bool()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			value = field.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			value = field.Uint()
+		case reflect.Float32, reflect.Float64:
+			value = field.Float()
+		}
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// jsonSchemaBound converts the min or max tag into a number, or returns nil if the field has no such bound.
+func jsonSchemaBound(v *variable, tag string) any {
+	bound, ok := v.fieldType.Tag.Lookup(tag)
+	if !ok {
+		return nil
+	}
+	bound = strings.TrimSpace(bound)
+
+	t := v.field.Type()
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n, err := parseIntBound(bound, t); err == nil {
+			return n
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n, err := strconv.ParseUint(bound, 0, 64); err == nil {
+			return n
+		}
+		// a negative bound is below the minimum of 0 anyway
+		if n, err := parseIntBound(bound, t); err == nil && n >= 0 {
+			return n
+		}
+	case reflect.Float32, reflect.Float64:
+		if f, err := strconv.ParseFloat(bound, 64); err == nil {
+			return f
+		}
+	}
+
+	return nil
+}
+
+// toJSONSchemaType converts Go types into JSON Schema types
+func toJSONSchemaType(t reflect.Type) string {
+	if t.Kind() == reflect.Ptr {
+		return toJSONSchemaType(t.Elem())
+	}
+
+	if implementsInterface(t) {
+		return "string"
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if t.PkgPath() == "time" && t.Name() == "Duration" {
+			return "string"
+		}
+		return "integer"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	}
+
+	return "string"
+}
+
+func isUnsigned(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,99 @@
+package envconfig
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONSchema(t *testing.T) {
+	var s struct {
+		Debug    bool
+		Port     int           `required:"true" desc:"listen port"`
+		Workers  uint8         `default:"4"`
+		Rate     float64       `default:"0.5"`
+		Verbose  *bool         `default:"true"`
+		Timeout  time.Duration `default:"30s"`
+		Level    logLevel      `names:"debug=0,info=1" default:"info"`
+		Fallback logLevel      `names:"debug=0,info=1" default:"0"`
+		Password string        `default:"hunter2" secret:"true"`
+		Database struct {
+			Host string `required:"true"`
+		}
+	}
+
+	data, err := JSONSchema(&s, WithPrefix("app"))
+	assert.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"type": "object",
+		"properties": {
+			"APP_DEBUG": {"type": "boolean"},
+			"APP_PORT": {"type": "integer", "description": "listen port"},
+			"APP_WORKERS": {"type": "integer", "default": 4, "minimum": 0},
+			"APP_RATE": {"type": "number", "default": 0.5},
+			"APP_VERBOSE": {"type": "boolean", "default": true},
+			"APP_TIMEOUT": {"type": "string", "default": "30s"},
+			"APP_LEVEL": {"type": ["string", "integer"], "default": "info", "enum": ["debug", "info", 0, 1]},
+			"APP_FALLBACK": {"type": ["string", "integer"], "default": 0, "enum": ["debug", "info", 0, 1]},
+			"APP_PASSWORD": {"type": "string"},
+			"APP_DATABASE_HOST": {"type": "string"}
+		},
+		"required": ["APP_PORT", "APP_DATABASE_HOST"],
+		"additionalProperties": true
+	}`, string(data))
+}
+
+func TestJSONSchemaInvalidSpecification(t *testing.T) {
+	_, err := JSONSchema(map[string]string{})
+	assert.Equal(t, ErrInvalidSpecification, err)
+}
+
+func TestJSONSchemaOneOfRange(t *testing.T) {
+	var s struct {
+		Format  string   `oneof:"json, text"`
+		Color   string   `oneof:"red,blue" oneof_fold:"true"`
+		Outputs []string `oneof:"stdout,file"`
+		Retries int      `min:"-1" max:"10"`
+		Workers uint     `max:"1e3"`
+		Ratio   *float64 `min:"0" max:"0.5"`
+	}
+
+	data, err := JSONSchema(&s)
+	assert.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"type": "object",
+		"properties": {
+			"FORMAT": {"type": "string", "enum": ["json", "text"]},
+			"COLOR": {"type": "string"},
+			"OUTPUTS": {"type": "string"},
+			"RETRIES": {"type": "integer", "minimum": -1, "maximum": 10},
+			"WORKERS": {"type": "integer", "minimum": 0, "maximum": 1000},
+			"RATIO": {"type": "number", "minimum": 0, "maximum": 0.5}
+		},
+		"additionalProperties": true
+	}`, string(data))
+}
+
+func TestJSONSchemaKeyFilter(t *testing.T) {
+	var s struct {
+		Port  int `required:"true"`
+		Debug bool
+	}
+
+	data, err := JSONSchema(&s, WithKeyFilter(func(key string) bool { return key != "PORT" }))
+	assert.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"type": "object",
+		"properties": {
+			"DEBUG": {"type": "boolean"}
+		},
+		"additionalProperties": true
+	}`, string(data))
+}
@@ -0,0 +1,82 @@
+package envconfig
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Kinds of sources accepted by the sources tag.
+const (
+	SourceEnv     = "env"
+	SourceFile    = "file"
+	SourceLiteral = "literal"
+)
+
+// source is a single entry of the sources tag
+type source struct {
+	kind    string
+	locator string
+}
+
+// parseSources parses a sources tag: a comma separated list of `kind:locator` entries,
+// where kind is one of env, file or literal.
+func parseSources(tag string) ([]source, error) {
+	var sources []source
+
+	for _, item := range strings.Split(tag, ",") {
+		kind, locator, found := strings.Cut(strings.TrimSpace(item), ":")
+		if !found {
+			return nil, fmt.Errorf("invalid sources item %q: expected kind:locator", item)
+		}
+
+		switch kind {
+		case SourceEnv, SourceFile:
+			if locator == "" {
+				return nil, fmt.Errorf("invalid sources item %q: empty %s locator", item, kind)
+			}
+		case SourceLiteral:
+		default:
+			return nil, fmt.Errorf("invalid sources item %q: unknown kind %q", item, kind)
+		}
+
+		sources = append(sources, source{kind: kind, locator: locator})
+	}
+
+	return sources, nil
+}
+
+// trySources tries the sources of the variable in order, the first non-empty value wins.
+// Missing files are skipped.
+func (v *variable) trySources() (value string, isLoaded bool, err error) {
+	for _, src := range v.sources {
+		switch src.kind {
+		case SourceEnv:
+			value, isLoaded = v.Opts.lookuper.Lookup(src.locator)
+		case SourceFile:
+			if v.Opts.fileLoadingDisabled {
+				continue
+			}
+			var bytes []byte
+			bytes, err = v.readFile(src.locator)
+			if errors.Is(err, os.ErrNotExist) {
+				err = nil
+				continue
+			}
+			if err != nil {
+				return "", false, err
+			}
+			value, isLoaded = string(bytes), true
+		case SourceLiteral:
+			value, isLoaded = src.locator, true
+		}
+
+		if isLoaded && strings.TrimSpace(value) != "" {
+			v.origin = src.kind
+			return value, true, nil
+		}
+	}
+
+	return "", false, nil
+}
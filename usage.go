@@ -2,6 +2,7 @@ package envconfig
 
 import (
 	"encoding"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -29,6 +30,23 @@ variables can be used:
 
 KEY	TYPE	DEFAULT	REQUIRED	DESCRIPTION
 {{range .}}{{usage_key .}}	{{usage_type .}}	{{usage_default .}}	{{usage_required .}}	{{usage_description .}}
+{{end}}`
+
+	// DefaultMarkdownFormat constant to use to display usage as a GitHub-flavored Markdown table
+	DefaultMarkdownFormat = `| Key | Type | Default | Required | Description |
+| --- | --- | --- | --- | --- |
+{{range .}}| {{usage_key . | usage_markdown}} | {{usage_type . | usage_markdown}} | {{usage_default . | usage_markdown}} | {{usage_required . | usage_markdown}} | {{usage_description . | usage_markdown}} |
+{{end}}`
+
+	// DefaultDebugFormat constant to use to display usage in a tabular format along with the current value of each variable,
+	// as resolved from the environment, files or defaults, secrets redacted
+	DefaultDebugFormat = `KEY	TYPE	DEFAULT	REQUIRED	CURRENT	DESCRIPTION
+{{range .}}{{usage_key .}}	{{usage_type .}}	{{usage_default .}}	{{usage_required .}}	{{usage_current .}}	{{usage_description .}}
+{{end}}`
+
+	// echoFormat is used to write the resolved configuration, see WithEchoOnProcess
+	echoFormat = `KEY	VALUE
+{{range .}}{{usage_key .}}	{{usage_value .}}
 {{end}}`
 )
 
@@ -40,7 +58,8 @@ var (
 )
 
 func implementsInterface(t reflect.Type) bool {
-	return t.Implements(decoderType) ||
+	return registeredDecoder(t) != nil ||
+		t.Implements(decoderType) ||
 		reflect.PtrTo(t).Implements(decoderType) ||
 		t.Implements(setterType) ||
 		reflect.PtrTo(t).Implements(setterType) ||
@@ -50,28 +69,67 @@ func implementsInterface(t reflect.Type) bool {
 		reflect.PtrTo(t).Implements(binaryUnmarshalerType)
 }
 
-// toTypeDescription converts Go types into a human readable description
-func toTypeDescription(t reflect.Type) string {
+// toTypeDescription converts Go types into a human readable description,
+// v provides the separators configured for the field.
+func toTypeDescription(t reflect.Type, v *variable) string {
+	if v.isJSON() {
+		return "JSON"
+	}
+
+	switch t {
+	case ipType:
+		return "IP Address"
+	case ipNetType:
+		return "CIDR Network"
+	case urlType:
+		return "URL"
+	case bigIntType:
+		return "Arbitrary-precision Integer"
+	case bigFloatType:
+		return "Arbitrary-precision Float"
+	}
+
+	// types decoding themselves are described by their name whatever their kind, e.g. a slice of bytes
+	if t.Kind() != reflect.Ptr && t.Name() != "" && implementsInterface(t) {
+		return t.Name()
+	}
+
 	switch t.Kind() {
-	case reflect.Array, reflect.Slice:
+	case reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return fmt.Sprintf("%s of %d bytes", toBytesDescription(v), t.Len())
+		}
+		return fmt.Sprintf("%s list of %d %s", separatedBy(v.delimiter()), t.Len(), toElemDescription(t.Elem(), v))
+	case reflect.Slice:
 		if t.Elem().Kind() == reflect.Uint8 {
-			return "String"
+			return toBytesDescription(v)
 		}
-		return fmt.Sprintf("Comma-separated list of %s", toTypeDescription(t.Elem()))
+		return fmt.Sprintf("%s list of %s", separatedBy(v.delimiter()), toElemDescription(t.Elem(), v))
 	case reflect.Map:
+		// pairs are split on the first separator only, slice values on the value delimiter
+		elem := toTypeDescription(t.Elem(), v)
+		e := t.Elem()
+		for e.Kind() == reflect.Ptr {
+			e = e.Elem()
+		}
+		if (e.Kind() == reflect.Slice || e.Kind() == reflect.Array) && e.Elem().Kind() != reflect.Uint8 {
+			elem = fmt.Sprintf("%s list of %s", separatedBy(v.valueDelimiter()), toTypeDescription(e.Elem(), v))
+		}
 		return fmt.Sprintf(
-			"Comma-separated list of %s:%s pairs",
-			toTypeDescription(t.Key()),
-			toTypeDescription(t.Elem()),
+			"%s list of %s%s%s pairs",
+			separatedBy(v.delimiter()),
+			toTypeDescription(t.Key(), v),
+			v.mapSeparator(),
+			elem,
 		)
 	case reflect.Ptr:
-		return toTypeDescription(t.Elem())
+		return toTypeDescription(t.Elem(), v)
 	case reflect.Struct:
-		if implementsInterface(t) && t.Name() != "" {
-			return t.Name()
-		}
 		return ""
 	case reflect.String:
+		if allowed := v.oneOf(); allowed != nil {
+			return fmt.Sprintf("One of %s", strings.Join(allowed, ", "))
+		}
 		name := t.Name()
 		if name != "" && name != "string" {
 			return name
@@ -84,47 +142,95 @@ func toTypeDescription(t reflect.Type) string {
 		}
 		return "True or False"
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if desc := unitDescription(v); desc != "" {
+			return desc
+		}
 		name := t.Name()
 		if name != "" && !strings.HasPrefix(name, "int") {
 			return name
 		}
 		return "Integer"
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if desc := unitDescription(v); desc != "" {
+			return desc
+		}
 		name := t.Name()
 		if name != "" && !strings.HasPrefix(name, "uint") {
 			return name
 		}
 		return "Unsigned Integer"
 	case reflect.Float32, reflect.Float64:
+		if desc := unitDescription(v); desc != "" {
+			return desc
+		}
 		name := t.Name()
 		if name != "" && !strings.HasPrefix(name, "float") {
 			return name
 		}
 		return "Float"
+	case reflect.Complex64, reflect.Complex128:
+		name := t.Name()
+		if name != "" && !strings.HasPrefix(name, "complex") {
+			return name
+		}
+		return "Complex Number"
 	}
 	return fmt.Sprintf("%+v", t)
 }
 
-// Usage writes usage information to stdout using the default header and table format
-func Usage(spec any, options ...Option) error {
-	// The default is to output the usage information as a table
-	// Create tabwriter instance to support table output
-	tabs := tabwriter.NewWriter(os.Stdout, 1, 0, 4, ' ', 0)
+// separatedBy names the delimiter of a list in type descriptions, e.g. Comma-separated or |-separated.
+func separatedBy(delimiter string) string {
+	switch delimiter {
+	case ",":
+		return "Comma-separated"
+	case ";":
+		return "Semicolon-separated"
+	case " ":
+		return "Space-separated"
+	}
+	return delimiter + "-separated"
+}
 
-	err := Usagef(spec, tabs, DefaultTableFormat, options...)
-	tabs.Flush()
-	return err
+// toElemDescription describes the elements of a list, naming the allowed values of a oneof tag without "One of".
+func toElemDescription(t reflect.Type, v *variable) string {
+	if allowed := v.oneOf(); allowed != nil {
+		return strings.Join(allowed, ", ")
+	}
+	return toTypeDescription(t, v)
 }
 
-// Usagef writes usage information to the specified io.Writer using the specified template specification
-func Usagef(spec any, out io.Writer, format string, options ...Option) error {
+// toBytesDescription describes the strings accepted by []byte fields according to the encoding tag.
+func toBytesDescription(v *variable) string {
+	switch v.fieldType.Tag.Get(TagEncoding) {
+	case EncodingBase64:
+		return "Base64-encoded String"
+	case EncodingHex:
+		return "Hex-encoded String"
+	}
+	return "String"
+}
+
+// unitDescription describes the quantities accepted by numeric fields with a unit tag, or returns an empty string.
+func unitDescription(v *variable) string {
+	switch v.fieldType.Tag.Get(TagUnit) {
+	case UnitBytes:
+		return "Byte Size (e.g. 512MiB)"
+	case UnitPercent:
+		return "Percentage (e.g. 5%)"
+	}
 
-	// Specify the default usage template functions
-	functions := template.FuncMap{
+	return ""
+}
+
+// usageFunctions returns the default usage template functions
+func usageFunctions() template.FuncMap {
+	return template.FuncMap{
 		"usage_key":         func(v variable) string { return v.key },
+		"usage_base_key":    func(v variable) string { return v.baseKey },
 		"usage_description": func(v variable) string { return v.fieldType.Tag.Get("desc") },
-		"usage_type":        func(v variable) string { return toTypeDescription(v.field.Type()) },
-		"usage_default":     func(v variable) string { return v.fieldType.Tag.Get("default") },
+		"usage_type":        func(v variable) string { return toTypeDescription(v.field.Type(), &v) },
+		"usage_default":     func(v variable) string { return usageDefault(&v) },
+		"usage_secret":      func(v variable) bool { return v.isSecret() },
 		"usage_required": func(v variable) (string, error) {
 			req := v.fieldType.Tag.Get("required")
 			if req != "" {
@@ -135,12 +241,93 @@ func Usagef(spec any, out io.Writer, format string, options ...Option) error {
 				if reqB {
 					req = "true"
 				}
+			} else if v.isRequired() {
+				// required without the tag, see WithRequiredByDefault
+				req = "true"
 			}
 			return req, nil
 		},
+		"usage_markdown": markdownEscape,
+		"usage_current":  func(v variable) (string, error) { return usageCurrent(&v) },
+		"usage_value": func(v variable) string {
+			if v.isSecret() {
+				return redactedValue
+			}
+			return formatValue(v.field)
+		},
+	}
+}
+
+// usageDefault returns the default tag of the variable, redacted for secret fields
+func usageDefault(v *variable) string {
+	value := v.fieldType.Tag.Get(TagDefault)
+	if v.isSecret() && value != "" {
+		return redactedValue
+	}
+	return value
+}
+
+// usageCurrent resolves the value the variable would be assigned, the default included, redacted for secret fields
+func usageCurrent(v *variable) (string, error) {
+	value, isLoaded, err := v.value()
+	if err != nil || !isLoaded {
+		return "", err
+	}
+	if v.isSecret() {
+		return redactedValue, nil
+	}
+	return value, nil
+}
+
+// markdownEscape makes text safe to put in a Markdown table cell: pipes are escaped and line breaks collapsed
+func markdownEscape(text string) string {
+	return strings.ReplaceAll(strings.Join(strings.Fields(text), " "), "|", `\|`)
+}
+
+// formatValue renders the current value of a field, nil pointers are rendered as empty strings
+func formatValue(field reflect.Value) string {
+	for field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return ""
+		}
+		field = field.Elem()
+	}
+
+	if field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.Uint8 && field.Type() != ipType {
+		return string(field.Bytes())
+	}
+
+	return fmt.Sprint(field.Interface())
+}
+
+// echo writes the resolved values of the variables to out as a table
+func echo(out io.Writer, vars []*variable) error {
+	tmpl, err := template.New("envconfig").Funcs(usageFunctions()).Parse(echoFormat)
+	if err != nil {
+		return err
 	}
 
-	tmpl, err := template.New("envconfig").Funcs(functions).Parse(format)
+	tabs := tabwriter.NewWriter(out, 1, 0, 4, ' ', 0)
+	err = tmpl.Execute(tabs, vars)
+	tabs.Flush()
+	return err
+}
+
+// Usage writes usage information to stdout using the default header and table format
+func Usage(spec any, options ...Option) error {
+	// The default is to output the usage information as a table
+	// Create tabwriter instance to support table output
+	tabs := tabwriter.NewWriter(os.Stdout, 1, 0, 4, ' ', 0)
+
+	err := Usagef(spec, tabs, DefaultTableFormat, options...)
+	tabs.Flush()
+	return err
+}
+
+// Usagef writes usage information to the specified io.Writer using the specified template specification
+func Usagef(spec any, out io.Writer, format string, options ...Option) error {
+
+	tmpl, err := template.New("envconfig").Funcs(usageFunctions()).Parse(format)
 	if err != nil {
 		return err
 	}
@@ -150,7 +337,7 @@ func Usagef(spec any, out io.Writer, format string, options ...Option) error {
 
 // Usaget writes usage information to the specified io.Writer using the specified template
 func Usaget(spec any, out io.Writer, tmpl *template.Template, options ...Option) error {
-	opts := defaultOptions().apply(options...)
+	opts := defaultOptions().apply(options...).forSpec(spec)
 
 	// gather first
 	infos, err := gatherInfo(spec, opts)
@@ -158,5 +345,62 @@ func Usaget(spec any, out io.Writer, tmpl *template.Template, options ...Option)
 		return err
 	}
 
-	return tmpl.Execute(out, infos)
+	return tmpl.Execute(out, filterVars(infos, opts))
+}
+
+// UsageJSON writes usage information to out as a JSON array of objects
+// with the key, type, default, required and description of each variable.
+func UsageJSON(spec any, out io.Writer, options ...Option) error {
+	opts := defaultOptions().apply(options...).forSpec(spec)
+
+	infos, err := gatherInfo(spec, opts)
+	if err != nil {
+		return err
+	}
+
+	type usageEntry struct {
+		Key         string `json:"key"`
+		Type        string `json:"type"`
+		Default     string `json:"default"`
+		Required    bool   `json:"required"`
+		Description string `json:"description"`
+	}
+
+	entries := make([]usageEntry, 0, len(infos))
+	for _, v := range filterVars(infos, opts) {
+		entries = append(entries, usageEntry{
+			Key:         v.key,
+			Type:        toTypeDescription(v.field.Type(), v),
+			Default:     usageDefault(v),
+			Required:    v.isRequired(),
+			Description: v.fieldType.Tag.Get("desc"),
+		})
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(entries)
+}
+
+// UsageMarkdown writes usage information to out as a GitHub-flavored Markdown table, see DefaultMarkdownFormat
+func UsageMarkdown(spec any, out io.Writer, options ...Option) error {
+	return Usagef(spec, out, DefaultMarkdownFormat, options...)
+}
+
+// UsageFunc calls fn for each variable of the specification with its key, type description, default,
+// whether it is required and its description, e.g. to log configuration requirements with a structured logger.
+// Defaults of secret fields are redacted.
+func UsageFunc(spec any, fn func(key, typ, def string, required bool, desc string), options ...Option) error {
+	opts := defaultOptions().apply(options...).forSpec(spec)
+
+	infos, err := gatherInfo(spec, opts)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range filterVars(infos, opts) {
+		fn(v.key, toTypeDescription(v.field.Type(), v), usageDefault(v), v.isRequired(), v.fieldType.Tag.Get("desc"))
+	}
+
+	return nil
 }
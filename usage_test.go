@@ -2,13 +2,17 @@ package envconfig
 
 import (
 	"bytes"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"log"
+	"net"
+	"net/url"
 	"os"
 	"strings"
 	"testing"
 	"text/tabwriter"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -147,3 +151,272 @@ func TestUsageBadFormat(t *testing.T) {
 
 	compareUsage(testUsageBadFormatResult, buf.String(), t)
 }
+
+func TestEchoOnProcess(t *testing.T) {
+	var s struct {
+		Port     int
+		Hosts    []string
+		Password string `secret:"true"`
+		Timeout  *time.Duration
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_PORT", "8080")
+	os.Setenv("ENV_CONFIG_HOSTS", "a,b")
+	os.Setenv("ENV_CONFIG_PASSWORD", "hunter2")
+
+	buf := new(bytes.Buffer)
+	err := Process(&s, WithPrefix("env_config"), WithEchoOnProcess(buf))
+	assert.NoError(t, err)
+
+	out := buf.String()
+	assert.NotContains(t, out, "hunter2")
+
+	var lines [][]string
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		lines = append(lines, strings.Fields(line))
+	}
+	assert.Equal(t, [][]string{
+		{"KEY", "VALUE"},
+		{"ENV_CONFIG_PORT", "8080"},
+		{"ENV_CONFIG_HOSTS", "[a", "b]"},
+		{"ENV_CONFIG_PASSWORD", "<redacted>"},
+		{"ENV_CONFIG_TIMEOUT"},
+	}, lines)
+}
+
+func TestEchoOnProcessSkippedOnError(t *testing.T) {
+	var s struct {
+		Port int
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_PORT", "eighty")
+
+	buf := new(bytes.Buffer)
+	err := Process(&s, WithPrefix("env_config"), WithEchoOnProcess(buf))
+	assert.Error(t, err)
+	assert.Empty(t, buf.String())
+}
+
+func TestUsageKeyFilter(t *testing.T) {
+	var s struct {
+		Port     int
+		Database struct {
+			Host string
+			Port int
+		}
+		Log struct {
+			Level string
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	err := Usagef(&s, buf, "{{range .}}{{usage_key .}}\n{{end}}", WithPrefix("app"), WithKeyFilter(func(key string) bool {
+		return strings.HasPrefix(key, "APP_DATABASE_")
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, "APP_DATABASE_HOST\nAPP_DATABASE_PORT\n", buf.String())
+}
+
+func TestUsageMapSeparator(t *testing.T) {
+	var s struct {
+		Endpoints map[string]string
+		Weights   map[string]int `map_separator:"="`
+	}
+
+	buf := new(bytes.Buffer)
+	err := Usagef(&s, buf, "{{range .}}{{usage_type .}}\n{{end}}")
+	assert.NoError(t, err)
+	assert.Equal(t, "Comma-separated list of String:String pairs\nComma-separated list of String=Integer pairs\n", buf.String())
+}
+
+func TestUsageNetTypes(t *testing.T) {
+	var s struct {
+		Address net.IP
+		Subnet  *net.IPNet
+		Proxy   url.URL
+		Mirrors []*url.URL
+	}
+
+	buf := new(bytes.Buffer)
+	err := Usagef(&s, buf, "{{range .}}{{usage_type .}}\n{{end}}")
+	assert.NoError(t, err)
+	assert.Equal(t, "IP Address\nCIDR Network\nURL\nComma-separated list of URL\n", buf.String())
+}
+
+func TestUsageJSON(t *testing.T) {
+	type Embedded struct {
+		Level string `default:"info"`
+	}
+	var s struct {
+		Embedded
+		Port     int `required:"true" desc:"listen port"`
+		Database struct {
+			Hosts []string `split_words:"true"`
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, UsageJSON(&s, buf, WithPrefix("app")))
+	assert.JSONEq(t, `[
+		{"key": "APP_LEVEL", "type": "String", "default": "info", "required": false, "description": ""},
+		{"key": "APP_PORT", "type": "Integer", "default": "", "required": true, "description": "listen port"},
+		{"key": "APP_DATABASE_HOSTS", "type": "Comma-separated list of String", "default": "", "required": false, "description": ""}
+	]`, buf.String())
+
+	buf.Reset()
+	assert.NoError(t, UsageJSON(&struct{}{}, buf))
+	assert.JSONEq(t, `[]`, buf.String())
+}
+
+func TestUsageComplexNumber(t *testing.T) {
+	var s struct {
+		Gain complex128
+	}
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, Usagef(&s, buf, "{{range .}}{{usage_type .}}\n{{end}}"))
+	assert.Equal(t, "Complex Number\n", buf.String())
+}
+
+func TestUsageMarkdown(t *testing.T) {
+	var s struct {
+		Port  int    `required:"true" desc:"listen port"`
+		Mode  string `default:"a|b" desc:"one of a|b,\n  see docs"`
+		Hosts []string
+	}
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, UsageMarkdown(&s, buf, WithPrefix("app")))
+	assert.Equal(t, "| Key | Type | Default | Required | Description |\n"+
+		"| --- | --- | --- | --- | --- |\n"+
+		"| APP_PORT | Integer |  | true | listen port |\n"+
+		"| APP_MODE | String | a\\|b |  | one of a\\|b, see docs |\n"+
+		"| APP_HOSTS | Comma-separated list of String |  |  |  |\n", buf.String())
+}
+
+func TestUsageSecret(t *testing.T) {
+	var s struct {
+		Password string `secret:"true" default:"hunter2"`
+		APIKey   string `secret:"true"`
+		User     string `default:"admin"`
+	}
+
+	buf := new(bytes.Buffer)
+	err := Usagef(&s, buf, "{{range .}}{{usage_key .}} {{usage_default .}} {{usage_secret .}}\n{{end}}", WithPrefix("app"))
+	assert.NoError(t, err)
+	assert.Equal(t, "APP_PASSWORD <redacted> true\nAPP_APIKEY  true\nAPP_USER admin false\n", buf.String())
+
+	buf.Reset()
+	assert.NoError(t, UsageJSON(&s, buf, WithPrefix("app")))
+	assert.NotContains(t, buf.String(), "hunter2")
+}
+
+func TestUsageEncodedBytes(t *testing.T) {
+	var s struct {
+		Raw  []byte
+		Salt []byte `encoding:"base64"`
+		Key  []byte `encoding:"hex"`
+	}
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, Usagef(&s, buf, "{{range .}}{{usage_type .}}\n{{end}}"))
+	assert.Equal(t, "String\nBase64-encoded String\nHex-encoded String\n", buf.String())
+}
+
+func TestUsageFunc(t *testing.T) {
+	var s struct {
+		Port     int    `required:"true" desc:"listen port"`
+		Password string `secret:"true" default:"hunter2"`
+		Database struct {
+			Hosts []string `default:"db1,db2"`
+		}
+	}
+
+	var lines []string
+	err := UsageFunc(&s, func(key, typ, def string, required bool, desc string) {
+		lines = append(lines, fmt.Sprintf("key=%s type=%q default=%q required=%t desc=%q", key, typ, def, required, desc))
+	}, WithPrefix("app"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		`key=APP_PORT type="Integer" default="" required=true desc="listen port"`,
+		`key=APP_PASSWORD type="String" default="<redacted>" required=false desc=""`,
+		`key=APP_DATABASE_HOSTS type="Comma-separated list of String" default="db1,db2" required=false desc=""`,
+	}, lines)
+
+	assert.ErrorIs(t, UsageFunc(s, func(string, string, string, bool, string) {}), ErrInvalidSpecification)
+}
+
+func TestUsageCurrent(t *testing.T) {
+	var s struct {
+		Port     int    `required:"true" desc:"listen port"`
+		Mode     string `default:"fast"`
+		Password string `secret:"true"`
+		Token    string `secret:"true"`
+		Host     string
+	}
+
+	os.Clearenv()
+	os.Setenv("APP_PORT", "8080")
+	os.Setenv("APP_PASSWORD", "s3cr3t")
+
+	buf := new(bytes.Buffer)
+	tabs := tabwriter.NewWriter(buf, 1, 0, 4, ' ', 0)
+	assert.NoError(t, Usagef(&s, tabs, DefaultDebugFormat, WithPrefix("app")))
+	assert.NoError(t, tabs.Flush())
+	assert.Equal(t, "KEY             TYPE       DEFAULT    REQUIRED    CURRENT       DESCRIPTION\n"+
+		"APP_PORT        Integer               true        8080          listen port\n"+
+		"APP_MODE        String     fast                   fast          \n"+
+		"APP_PASSWORD    String                            <redacted>    \n"+
+		"APP_TOKEN       String                                          \n"+
+		"APP_HOST        String                                          \n", buf.String())
+
+	os.Setenv("APP_HOST_FILE", "testdata/missing.txt")
+	assert.ErrorIs(t, Usagef(&s, buf, DefaultDebugFormat, WithPrefix("app")), os.ErrNotExist)
+}
+
+func TestUsageTextUnmarshalerDefault(t *testing.T) {
+	var s struct {
+		Background hexColor   `default:"#FF8800"`
+		Foreground *hexColor  `default:"#000000"`
+		Palette    []hexColor `default:"#ffffff,#000000"`
+		Started    time.Time  `default:"2024-01-02T03:04:05Z"`
+	}
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, Usagef(&s, buf, "{{range .}}{{usage_type .}}={{usage_default .}}\n{{end}}"))
+	assert.Equal(t, "hexColor=#FF8800\n"+
+		"hexColor=#000000\n"+
+		"Comma-separated list of hexColor=#ffffff,#000000\n"+
+		"Time=2024-01-02T03:04:05Z\n", buf.String())
+}
+
+func TestUsageOneOf(t *testing.T) {
+	var s struct {
+		LogLevel string   `oneof:"debug, info, warn, error"`
+		Formats  []string `oneof:"json,text"`
+	}
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, Usagef(&s, buf, "{{range .}}{{usage_type .}}\n{{end}}"))
+	assert.Equal(t, "One of debug, info, warn, error\nComma-separated list of json, text\n", buf.String())
+}
+
+func TestUsageRequiredByDefault(t *testing.T) {
+	var s struct {
+		Host    string
+		Port    int    `default:"80"`
+		Mode    string `optional:"true"`
+		Replica string `required:"false"`
+	}
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, Usagef(&s, buf, "{{range .}}{{usage_key .}}={{usage_required .}}\n{{end}}", WithRequiredByDefault()))
+	assert.Equal(t, "HOST=true\nPORT=\nMODE=\nREPLICA=false\n", buf.String())
+
+	buf.Reset()
+	assert.NoError(t, UsageMarkdown(&s, buf, WithRequiredByDefault()))
+	assert.Contains(t, buf.String(), "| HOST | String |  | true |  |")
+}
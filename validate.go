@@ -0,0 +1,461 @@
+package envconfig
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Validatable is implemented by specs and nested structs checking themselves once populated.
+type Validatable interface {
+	Validate() error
+}
+
+// validateSpec invokes Validate on the nested structs of the spec, then on the spec itself.
+// Errors of nested structs are prefixed with their field path.
+func validateSpec(spec reflect.Value, path string) error {
+	typ := spec.Type()
+	for i := 0; i < spec.NumField(); i++ {
+		field := spec.Field(i)
+		fieldType := typ.Field(i)
+		if !fieldType.IsExported() || isTrue(fieldType.Tag.Get(TagIgnored)) {
+			continue
+		}
+
+		for field.Kind() == reflect.Ptr && !field.IsNil() {
+			field = field.Elem()
+		}
+		if field.Kind() != reflect.Struct || !isNestedStruct(field) {
+			continue
+		}
+
+		fieldPath := fieldType.Name
+		if path != "" {
+			fieldPath = path + "." + fieldPath
+		}
+		if err := validateSpec(field, fieldPath); err != nil {
+			return err
+		}
+	}
+
+	var validatable Validatable
+	interfaceFrom(spec, func(v interface{}, ok *bool) { validatable, *ok = v.(Validatable) })
+	if validatable == nil {
+		return nil
+	}
+
+	if err := validatable.Validate(); err != nil {
+		if path == "" {
+			return fmt.Errorf("validation failed: %w", err)
+		}
+		return fmt.Errorf("validation of %s failed: %w", path, err)
+	}
+
+	return nil
+}
+
+// validateDefaults converts the default tag of each variable to the type of its field without assigning it,
+// and returns all the defaults that fail to convert joined together.
+func validateDefaults(vars []*variable) error {
+	var errs []error
+	for _, v := range vars {
+		tag, ok := v.fieldType.Tag.Lookup(TagDefault)
+		if !ok {
+			continue
+		}
+
+		value, err := v.resolveDefault(tag)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		value, err = v.applyPipe(value)
+		if err == nil {
+			err = processField(value, reflect.New(v.field.Type()).Elem(), v)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid default %q of field %s: %w", value, v.fieldType.Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateField runs tag based checks against a field once its value is assigned.
+func validateField(value string, v *variable) error {
+	if isTrue(v.fieldType.Tag.Get(TagExistingFile)) {
+		if err := checkPath(value, false); err != nil {
+			return err
+		}
+	}
+
+	if isTrue(v.fieldType.Tag.Get(TagExistingDir)) {
+		if err := checkPath(value, true); err != nil {
+			return err
+		}
+	}
+
+	if err := checkLength(v); err != nil {
+		return err
+	}
+
+	if err := checkRange(v); err != nil {
+		return err
+	}
+
+	if err := checkOneOf(v); err != nil {
+		return err
+	}
+
+	if name, ok := v.fieldType.Tag.Lookup(TagUnique); ok {
+		if err := checkUnique(name, v.field); err != nil {
+			return err
+		}
+	}
+
+	if name, ok := v.fieldType.Tag.Lookup(TagValidate); ok {
+		if err := checkNamed(name, v); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkUnique ensures that the named sub-field has distinct values across the elements of a slice of structs.
+func checkUnique(name string, field reflect.Value) error {
+	for field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return nil
+		}
+		field = field.Elem()
+	}
+
+	if field.Kind() != reflect.Slice && field.Kind() != reflect.Array {
+		return fmt.Errorf("unique is not supported for type %s", field.Type())
+	}
+
+	seen := make(map[any]struct{}, field.Len())
+	for i := 0; i < field.Len(); i++ {
+		elem := field.Index(i)
+		for elem.Kind() == reflect.Ptr && !elem.IsNil() {
+			elem = elem.Elem()
+		}
+		if elem.Kind() != reflect.Struct {
+			return fmt.Errorf("unique requires struct elements, got %s", elem.Type())
+		}
+
+		value := elem.FieldByName(name)
+		if !value.IsValid() {
+			return fmt.Errorf("unique: %s has no field %s", elem.Type(), name)
+		}
+		if !value.Type().Comparable() {
+			return fmt.Errorf("unique: field %s of type %s is not comparable", name, value.Type())
+		}
+
+		key := value.Interface()
+		if _, found := seen[key]; found {
+			return fmt.Errorf("duplicate %s %v", name, key)
+		}
+		seen[key] = struct{}{}
+	}
+
+	return nil
+}
+
+// checkValidation reports validate tags naming an unknown validation, whether or not the field is set.
+func checkValidation(name string) error {
+	switch name {
+	case ValidatePort:
+		return nil
+	}
+
+	return fmt.Errorf("unknown validation %q", name)
+}
+
+// checkNamed runs the named validation from the validate tag.
+func checkNamed(name string, v *variable) error {
+	switch name {
+	case ValidatePort:
+		n, ok := integerValue(v.field)
+		if !ok {
+			return fmt.Errorf("validation %q is not supported for type %s", name, v.field.Type())
+		}
+		if n < 1 || n > 65535 {
+			return fmt.Errorf("port must be between 1 and 65535, got %d", n)
+		}
+	default:
+		return fmt.Errorf("unknown validation %q", name)
+	}
+
+	return nil
+}
+
+// integerValue returns the value of an integer field, dereferencing pointers.
+func integerValue(field reflect.Value) (int64, bool) {
+	for field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return 0, false
+		}
+		field = field.Elem()
+	}
+
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return field.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u := field.Uint()
+		if u > math.MaxInt64 {
+			return math.MaxInt64, true
+		}
+		return int64(u), true
+	}
+
+	return 0, false
+}
+
+// checkLength enforces the minlen and maxlen tags on slices, maps and strings.
+func checkLength(v *variable) error {
+	minTag, hasMin := v.fieldType.Tag.Lookup(TagMinLen)
+	maxTag, hasMax := v.fieldType.Tag.Lookup(TagMaxLen)
+	if !hasMin && !hasMax {
+		return nil
+	}
+
+	field := v.field
+	for field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return nil
+		}
+		field = field.Elem()
+	}
+
+	var length int
+	unit := "items"
+	switch field.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Array:
+		length = field.Len()
+	case reflect.String:
+		length = utf8.RuneCountInString(field.String())
+		unit = "characters"
+	default:
+		return fmt.Errorf("minlen and maxlen are not supported for type %s", field.Type())
+	}
+
+	if hasMin {
+		minLen, err := strconv.Atoi(minTag)
+		if err != nil {
+			return fmt.Errorf("invalid minlen %q: %w", minTag, err)
+		}
+		if length < minLen {
+			return fmt.Errorf("at least %d %s required, got %d", minLen, unit, length)
+		}
+	}
+
+	if hasMax {
+		maxLen, err := strconv.Atoi(maxTag)
+		if err != nil {
+			return fmt.Errorf("invalid maxlen %q: %w", maxTag, err)
+		}
+		if length > maxLen {
+			return fmt.Errorf("at most %d %s allowed, got %d", maxLen, unit, length)
+		}
+	}
+
+	return nil
+}
+
+// checkRange enforces the min and max tags on numeric fields, both bounds included.
+// Bounds may be negative or in scientific notation, and are durations for time.Duration fields.
+func checkRange(v *variable) error {
+	minTag, hasMin := v.fieldType.Tag.Lookup(TagMin)
+	maxTag, hasMax := v.fieldType.Tag.Lookup(TagMax)
+	if !hasMin && !hasMax {
+		return nil
+	}
+
+	field := v.field
+	for field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return nil
+		}
+		field = field.Elem()
+	}
+
+	// compare returns -1, 0 or +1 as the value of the field is less than, equal to or greater than bound
+	var compare func(bound string) (int, error)
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		compare = func(bound string) (int, error) {
+			b, err := parseIntBound(bound, field.Type())
+			if err != nil {
+				return 0, err
+			}
+			switch n := field.Int(); {
+			case n < b:
+				return -1, nil
+			case n > b:
+				return 1, nil
+			}
+			return 0, nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		compare = func(bound string) (int, error) {
+			b, err := strconv.ParseUint(bound, 0, 64)
+			if err != nil {
+				n, intErr := parseIntBound(bound, field.Type())
+				if intErr != nil {
+					return 0, intErr
+				}
+				if n < 0 {
+					// any unsigned value is greater than a negative bound
+					return 1, nil
+				}
+				b = uint64(n)
+			}
+			switch n := field.Uint(); {
+			case n < b:
+				return -1, nil
+			case n > b:
+				return 1, nil
+			}
+			return 0, nil
+		}
+	case reflect.Float32, reflect.Float64:
+		compare = func(bound string) (int, error) {
+			// rounded like the value of the field, so that a float32 set to the bound is within the range
+			b, err := strconv.ParseFloat(bound, field.Type().Bits())
+			if err != nil {
+				return 0, err
+			}
+			switch n := field.Float(); {
+			case n < b:
+				return -1, nil
+			case n > b:
+				return 1, nil
+			}
+			return 0, nil
+		}
+	default:
+		return fmt.Errorf("min and max are not supported for type %s", field.Type())
+	}
+
+	if hasMin {
+		minTag = strings.TrimSpace(minTag)
+		c, err := compare(minTag)
+		if err != nil {
+			return fmt.Errorf("invalid min %q: %w", minTag, err)
+		}
+		if c < 0 {
+			return fmt.Errorf("must be at least %s, got %v", minTag, field.Interface())
+		}
+	}
+
+	if hasMax {
+		maxTag = strings.TrimSpace(maxTag)
+		c, err := compare(maxTag)
+		if err != nil {
+			return fmt.Errorf("invalid max %q: %w", maxTag, err)
+		}
+		if c > 0 {
+			return fmt.Errorf("must be at most %s, got %v", maxTag, field.Interface())
+		}
+	}
+
+	return nil
+}
+
+// checkOneOf ensures that a string field, or each element of a slice of strings, holds one of the values of the
+// oneof tag. With oneof_fold the case is ignored, and values are set to the allowed value as spelled in the tag.
+func checkOneOf(v *variable) error {
+	allowed := v.oneOf()
+	if allowed == nil {
+		return nil
+	}
+
+	field := v.field
+	for field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return nil
+		}
+		field = field.Elem()
+	}
+
+	fold := isTrue(v.fieldType.Tag.Get(TagOneOfFold))
+	switch {
+	case field.Kind() == reflect.String:
+		return matchOneOf(field, allowed, fold)
+	case (field.Kind() == reflect.Slice || field.Kind() == reflect.Array) && field.Type().Elem().Kind() == reflect.String:
+		for i := 0; i < field.Len(); i++ {
+			if err := matchOneOf(field.Index(i), allowed, fold); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return fmt.Errorf("oneof is not supported for type %s", field.Type())
+}
+
+// matchOneOf ensures that the string value is one of allowed, ignoring the case if fold is set.
+func matchOneOf(value reflect.Value, allowed []string, fold bool) error {
+	s := value.String()
+	for _, a := range allowed {
+		if s == a {
+			return nil
+		}
+		if fold && strings.EqualFold(s, a) {
+			value.SetString(a)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("must be one of %s, got %q", strings.Join(allowed, ", "), s)
+}
+
+// parseIntBound parses a bound of an integer field, either an integer, a whole number in scientific notation
+// like 1e6, or a duration for time.Duration fields.
+func parseIntBound(bound string, typ reflect.Type) (int64, error) {
+	if typ == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(bound)
+		return int64(d), err
+	}
+
+	if n, err := strconv.ParseInt(bound, 0, 64); err == nil {
+		return n, nil
+	}
+
+	f, err := strconv.ParseFloat(bound, 64)
+	if err != nil {
+		return 0, err
+	}
+	if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+		return 0, fmt.Errorf("%s is not an integer", bound)
+	}
+
+	return int64(f), nil
+}
+
+// checkPath ensures that path exists and is either a regular file or a directory.
+func checkPath(path string, isDir bool) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	if isDir && !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", path)
+	}
+	if !isDir && !info.Mode().IsRegular() {
+		return fmt.Errorf("%s is not a regular file", path)
+	}
+
+	return nil
+}
@@ -0,0 +1,444 @@
+package envconfig
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateExistingPaths(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(file, []byte("key: value"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	type spec struct {
+		ConfigPath string `existing_file:"true"`
+		DataDir    string `existing_dir:"true"`
+	}
+
+	tests := []struct {
+		name      string
+		config    string
+		data      string
+		wantField string
+	}{
+		{
+			name:   "existing",
+			config: file,
+			data:   dir,
+		},
+		{
+			name:      "missing file",
+			config:    filepath.Join(dir, "missing.yaml"),
+			data:      dir,
+			wantField: "ConfigPath",
+		},
+		{
+			name:      "directory instead of file",
+			config:    dir,
+			data:      dir,
+			wantField: "ConfigPath",
+		},
+		{
+			name:      "file instead of directory",
+			config:    file,
+			data:      file,
+			wantField: "DataDir",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s spec
+
+			os.Clearenv()
+			os.Setenv("ENV_CONFIG_CONFIGPATH", tt.config)
+			os.Setenv("ENV_CONFIG_DATADIR", tt.data)
+
+			err := Process(&s, WithPrefix("env_config"))
+			if tt.wantField == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			v, ok := err.(*ParseError)
+			if !ok {
+				t.Fatalf("expected ParseError, got %T %v", err, err)
+			}
+			assert.Equal(t, tt.wantField, v.FieldName)
+		})
+	}
+}
+
+func TestValidateLength(t *testing.T) {
+	type spec struct {
+		Upstreams []string       `minlen:"2" maxlen:"3"`
+		Weights   map[string]int `minlen:"2" maxlen:"3"`
+	}
+
+	tests := []struct {
+		name      string
+		upstreams string
+		weights   string
+		wantError string
+	}{
+		{
+			name:      "slice under",
+			upstreams: "a",
+			weights:   "a:1,b:2",
+			wantError: "at least 2 items required, got 1",
+		},
+		{
+			name:      "within",
+			upstreams: "a,b,c",
+			weights:   "a:1,b:2,c:3",
+		},
+		{
+			name:      "slice over",
+			upstreams: "a,b,c,d",
+			weights:   "a:1,b:2",
+			wantError: "at most 3 items allowed, got 4",
+		},
+		{
+			name:      "map under",
+			upstreams: "a,b",
+			weights:   "a:1",
+			wantError: "at least 2 items required, got 1",
+		},
+		{
+			name:      "map over",
+			upstreams: "a,b",
+			weights:   "a:1,b:2,c:3,d:4",
+			wantError: "at most 3 items allowed, got 4",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s spec
+
+			os.Clearenv()
+			os.Setenv("ENV_CONFIG_UPSTREAMS", tt.upstreams)
+			os.Setenv("ENV_CONFIG_WEIGHTS", tt.weights)
+
+			err := Process(&s, WithPrefix("env_config"))
+			if tt.wantError == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			v, ok := err.(*ParseError)
+			if !ok {
+				t.Fatalf("expected ParseError, got %T %v", err, err)
+			}
+			assert.EqualError(t, v.Err, tt.wantError)
+		})
+	}
+}
+
+func TestValidateRange(t *testing.T) {
+	type spec struct {
+		Port      int           `min:"1" max:"65535"`
+		Offset    int8          `min:"-10"`
+		Workers   uint          `max:"1e3"`
+		Retries   *uint16       `min:"-1" max:"5"`
+		Ratio     float64       `min:"-1.5e-1" max:"0.5"`
+		Timeout   time.Duration `min:"100ms" max:"1m"`
+		Threshold float32       `max:" 2.5 "`
+		Scale     float32       `max:"0.1"`
+	}
+
+	tests := []struct {
+		name      string
+		key       string
+		value     string
+		wantError string
+	}{
+		{name: "within", key: "PORT", value: "8080"},
+		{name: "lower bound", key: "PORT", value: "1"},
+		{name: "upper bound", key: "PORT", value: "65535"},
+		{name: "under", key: "PORT", value: "0", wantError: "must be at least 1, got 0"},
+		{name: "over", key: "PORT", value: "65536", wantError: "must be at most 65535, got 65536"},
+		{name: "negative min", key: "OFFSET", value: "-10"},
+		{name: "under negative min", key: "OFFSET", value: "-11", wantError: "must be at least -10, got -11"},
+		{name: "scientific max", key: "WORKERS", value: "1000"},
+		{name: "over scientific max", key: "WORKERS", value: "1001", wantError: "must be at most 1e3, got 1001"},
+		{name: "unsigned with negative min", key: "RETRIES", value: "0"},
+		{name: "pointer over", key: "RETRIES", value: "6", wantError: "must be at most 5, got 6"},
+		{name: "float within", key: "RATIO", value: "-0.15"},
+		{name: "float under", key: "RATIO", value: "-0.2", wantError: "must be at least -1.5e-1, got -0.2"},
+		{name: "float over", key: "RATIO", value: "0.51", wantError: "must be at most 0.5, got 0.51"},
+		{name: "duration under", key: "TIMEOUT", value: "10ms", wantError: "must be at least 100ms, got 10ms"},
+		{name: "duration over", key: "TIMEOUT", value: "2m", wantError: "must be at most 1m, got 2m0s"},
+		{name: "trimmed bound", key: "THRESHOLD", value: "3", wantError: "must be at most 2.5, got 3"},
+		{name: "float32 at bound", key: "SCALE", value: "0.1"},
+		{name: "float32 over", key: "SCALE", value: "0.11", wantError: "must be at most 0.1, got 0.11"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s spec
+
+			os.Clearenv()
+			os.Setenv("ENV_CONFIG_TIMEOUT", "1s")
+			os.Setenv("ENV_CONFIG_"+tt.key, tt.value)
+
+			err := Process(&s, WithPrefix("env_config"))
+			if tt.wantError == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			v, ok := err.(*ParseError)
+			if !ok {
+				t.Fatalf("expected ParseError, got %T %v", err, err)
+			}
+			assert.EqualError(t, v.Err, tt.wantError)
+		})
+	}
+
+	// the zero value of unset fields is not checked
+	os.Clearenv()
+	assert.NoError(t, Process(&spec{}, WithPrefix("env_config")))
+
+	var invalid struct {
+		Count int    `min:"1.5"`
+		Name  string `max:"3"`
+	}
+	os.Setenv("ENV_CONFIG_COUNT", "2")
+	err := Process(&invalid, WithPrefix("env_config"))
+	assert.ErrorContains(t, err, `invalid min "1.5": 1.5 is not an integer`)
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_NAME", "abc")
+	err = Process(&invalid, WithPrefix("env_config"))
+	assert.ErrorContains(t, err, "min and max are not supported for type string")
+}
+
+func TestValidateOneOf(t *testing.T) {
+	type spec struct {
+		LogLevel string   `oneof:"debug, info, warn, error" default:"info"`
+		Format   *string  `oneof:"json,text" oneof_fold:"true"`
+		Secret   string   `oneof:"a,b" secret:"true"`
+		Outputs  []string `oneof:"stdout,file" oneof_fold:"true"`
+	}
+
+	os.Clearenv()
+
+	var s spec
+	if assert.NoError(t, Process(&s, WithPrefix("env_config"))) {
+		assert.Equal(t, "info", s.LogLevel)
+		assert.Nil(t, s.Format)
+	}
+
+	os.Setenv("ENV_CONFIG_LOGLEVEL", "warn")
+	os.Setenv("ENV_CONFIG_FORMAT", "JSON")
+	os.Setenv("ENV_CONFIG_OUTPUTS", "File,stdout")
+	s = spec{}
+	if assert.NoError(t, Process(&s, WithPrefix("env_config"))) {
+		assert.Equal(t, "warn", s.LogLevel)
+		if assert.NotNil(t, s.Format) {
+			assert.Equal(t, "json", *s.Format)
+		}
+		assert.Equal(t, []string{"file", "stdout"}, s.Outputs)
+	}
+
+	os.Setenv("ENV_CONFIG_OUTPUTS", "stdout,syslog")
+	err := Process(&spec{}, WithPrefix("env_config"))
+	assert.ErrorContains(t, err, `must be one of stdout, file, got "syslog"`)
+	os.Unsetenv("ENV_CONFIG_OUTPUTS")
+
+	// case sensitive by default
+	os.Setenv("ENV_CONFIG_LOGLEVEL", "WARN")
+	err = Process(&spec{}, WithPrefix("env_config"))
+	v, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("expected ParseError, got %T %v", err, err)
+	}
+	assert.Equal(t, "LogLevel", v.FieldName)
+	assert.EqualError(t, v.Err, `must be one of debug, info, warn, error, got "WARN"`)
+
+	os.Setenv("ENV_CONFIG_LOGLEVEL", "info")
+	os.Setenv("ENV_CONFIG_SECRET", "c")
+	err = Process(&spec{}, WithPrefix("env_config"))
+	assert.ErrorContains(t, err, `must be one of a, b, got "<redacted>"`)
+
+	var invalid struct {
+		Count int `oneof:"1,2"`
+	}
+	os.Setenv("ENV_CONFIG_COUNT", "1")
+	err = Process(&invalid, WithPrefix("env_config"))
+	assert.ErrorContains(t, err, "oneof is not supported for type int")
+}
+
+func TestValidatePort(t *testing.T) {
+	tests := []struct {
+		value     string
+		wantError string
+	}{
+		{value: "1"},
+		{value: "8080"},
+		{value: "65535"},
+		{value: "0", wantError: "port must be between 1 and 65535, got 0"},
+		{value: "70000", wantError: "port must be between 1 and 65535, got 70000"},
+		{value: "-1", wantError: "port must be between 1 and 65535, got -1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			var s struct {
+				Port int `validate:"port"`
+			}
+
+			os.Clearenv()
+			os.Setenv("ENV_CONFIG_PORT", tt.value)
+
+			err := Process(&s, WithPrefix("env_config"))
+			if tt.wantError == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			v, ok := err.(*ParseError)
+			if !ok {
+				t.Fatalf("expected ParseError, got %T %v", err, err)
+			}
+			assert.EqualError(t, v.Err, tt.wantError)
+		})
+	}
+
+	t.Run("unknown validation", func(t *testing.T) {
+		var s struct {
+			Port uint16 `validate:"socket"`
+		}
+
+		// reported even when the field is unset
+		os.Clearenv()
+
+		err := Process(&s, WithPrefix("env_config"))
+		assert.ErrorIs(t, err, ErrInvalidSpecification)
+		assert.ErrorContains(t, err, `field Port: unknown validation "socket"`)
+	})
+}
+
+type upstream struct {
+	Name string
+	Addr string
+}
+
+func (u *upstream) Set(value string) error {
+	name, addr, found := strings.Cut(value, "=")
+	if !found {
+		return fmt.Errorf("expected name=addr, got %q", value)
+	}
+	u.Name, u.Addr = name, addr
+	return nil
+}
+
+func TestValidateUnique(t *testing.T) {
+	type spec struct {
+		Upstreams []upstream `unique:"Name"`
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_UPSTREAMS", "a=10.0.0.1,b=10.0.0.2")
+
+	var s spec
+	err := Process(&s, WithPrefix("env_config"))
+	assert.NoError(t, err)
+	assert.Equal(t, []upstream{{"a", "10.0.0.1"}, {"b", "10.0.0.2"}}, s.Upstreams)
+
+	os.Setenv("ENV_CONFIG_UPSTREAMS", "a=10.0.0.1,b=10.0.0.2,a=10.0.0.3")
+
+	err = Process(&s, WithPrefix("env_config"))
+	v, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("expected ParseError, got %T %v", err, err)
+	}
+	assert.EqualError(t, v.Err, "duplicate Name a")
+}
+
+type validatedDatabase struct {
+	Host string
+	Port int
+}
+
+func (d *validatedDatabase) Validate() error {
+	if d.Port == 0 {
+		return errors.New("port is not set")
+	}
+	return nil
+}
+
+type validatedCache struct {
+	Primary validatedDatabase
+	Replica *validatedDatabase
+}
+
+type validatedSpec struct {
+	Name     string
+	Database validatedDatabase
+	Cache    validatedCache
+}
+
+func (s validatedSpec) Validate() error {
+	if s.Name == "" {
+		return errors.New("name is not set")
+	}
+	return nil
+}
+
+func TestValidatable(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_NAME", "app")
+	os.Setenv("ENV_CONFIG_DATABASE_PORT", "5432")
+	os.Setenv("ENV_CONFIG_CACHE_PRIMARY_PORT", "6379")
+	os.Setenv("ENV_CONFIG_CACHE_REPLICA_PORT", "6380")
+	assert.NoError(t, Process(&validatedSpec{}, WithPrefix("env_config")))
+
+	os.Unsetenv("ENV_CONFIG_CACHE_REPLICA_PORT")
+	err := Process(&validatedSpec{}, WithPrefix("env_config"))
+	assert.EqualError(t, err, "validation of Cache.Replica failed: port is not set")
+
+	os.Unsetenv("ENV_CONFIG_DATABASE_PORT")
+	err = Process(&validatedSpec{}, WithPrefix("env_config"))
+	assert.EqualError(t, err, "validation of Database failed: port is not set")
+
+	os.Setenv("ENV_CONFIG_DATABASE_PORT", "5432")
+	os.Setenv("ENV_CONFIG_CACHE_REPLICA_PORT", "6380")
+	os.Setenv("ENV_CONFIG_NAME", "")
+	err = Process(&validatedSpec{}, WithPrefix("env_config"))
+	assert.EqualError(t, err, "validation failed: name is not set")
+
+	assert.NoError(t, Process(&validatedSpec{}, WithPrefix("env_config"), WithoutValidation()))
+}
+
+func TestValidateDefaults(t *testing.T) {
+	var s struct {
+		Timeout  time.Duration `default:"30x"`
+		Retries  int           `default:"three"`
+		Host     string        `default:"localhost"`
+		Interval time.Duration `default:"1m"`
+		Token    string        `pipe:"base64decode" default:"dG9rZW4="`
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_TIMEOUT", "30s")
+	os.Setenv("ENV_CONFIG_RETRIES", "3")
+
+	assert.NoError(t, Process(&s, WithPrefix("env_config")))
+
+	s.Host = ""
+	err := Process(&s, WithPrefix("env_config"), WithValidateDefaults())
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), `invalid default "30x" of field Timeout`)
+		assert.Contains(t, err.Error(), `invalid default "three" of field Retries`)
+		assert.NotContains(t, err.Error(), "Interval")
+		assert.NotContains(t, err.Error(), "Token")
+	}
+	assert.Equal(t, "", s.Host)
+}
@@ -1,35 +1,112 @@
 package envconfig
 
 import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"reflect"
-	"regexp"
 	"strconv"
 	"strings"
+	"syscall"
+	"time"
+	"unicode"
+	"unicode/utf8"
+	"unsafe"
 )
 
 const (
-	TagEnvconfig  = "envconfig"
-	TagIgnored    = "ignored"
-	TagDefault    = "default"
-	TagSplitWords = "split_words"
-	TagRequired   = "required"
-	TagFile       = "file"
+	TagEnvconfig      = "envconfig"
+	TagIgnored        = "ignored"
+	TagDefault        = "default"
+	TagSplitWords     = "split_words"
+	TagRequired       = "required"
+	TagFile           = "file"
+	TagSecret         = "secret"
+	TagEnvPrefix      = "envprefix"
+	TagNames          = "names"
+	TagKeepEmpty      = "keep_empty"
+	TagSecretFormat   = "secret_format"
+	TagExistingFile   = "existing_file"
+	TagExistingDir    = "existing_dir"
+	TagFlags          = "flags"
+	TagSources        = "sources"
+	TagEncoding       = "encoding"
+	TagAs             = "as"
+	TagUnit           = "unit"
+	TagMinLen         = "minlen"
+	TagMaxLen         = "maxlen"
+	TagMin            = "min"
+	TagMax            = "max"
+	TagDefaultFrom    = "default_from"
+	TagValidate       = "validate"
+	TagDelimiter      = "delimiter"
+	TagRaw            = "raw"
+	TagMapSeparator   = "map_separator"
+	TagUnique         = "unique"
+	TagPipe           = "pipe"
+	TagTimeFormat     = "time_format"
+	TagLocation       = "location"
+	TagRequiredIf     = "required_if"
+	TagPrefix         = "prefix"
+	TagOptional       = "optional"
+	TagAliases        = "aliases"
+	TagJSON           = "json"
+	TagEmptyAsUnset   = "empty_as_unset"
+	TagValueDelimiter = "value_delimiter"
+	TagFileInline     = "file_inline"
+	TagOneOf          = "oneof"
+	TagOneOfFold      = "oneof_fold"
+)
+
+// ValidatePort is the validate tag value restricting integer fields to TCP/UDP port numbers.
+const ValidatePort = "port"
+
+// AsDuration is the value of the as tag making integer fields accept durations.
+const AsDuration = "duration"
+
+// Values of the unit tag making numeric fields accept human-readable quantities, see bytesValue and percentValue.
+const (
+	UnitBytes   = "bytes"
+	UnitPercent = "percent"
+)
+
+// Encodings of []byte fields accepted by the encoding tag.
+const (
+	EncodingBase64 = "base64"
+	EncodingHex    = "hex"
 )
 
 // variable maintains information about the configuration variable
 type variable struct {
 	key       string
+	baseKey   string
+	path      []string
 	altKey    string
+	aliases   []string
 	fieldType reflect.StructField
 	field     reflect.Value
+	parent    reflect.Value
+	sources   []source
+	pipe      []namedTransformer
+	location  *time.Location
+	origin    string // where the last resolved value came from, see Explain
+	resolved  string // the value assigned to the field, see ProcessResult
+	// lazyPointers are the nil struct pointers allocated to reach the field, see WithLazyStructPointers
+	lazyPointers []reflect.Value
+	// elemDelimiter replaces the delimiter while parsing slice values of a map
+	elemDelimiter string
 	// Tags      reflect.StructTag
 	Opts *options
 }
 
 // GatherInfo gathers information about the specified struct
 func gatherInfo(spec any, opts *options) (vars []*variable, err error) {
+	if opts.err != nil {
+		return nil, opts.err
+	}
+
 	s := reflect.ValueOf(spec)
 
 	if s.Kind() != reflect.Ptr {
@@ -47,10 +124,20 @@ func gatherInfo(spec any, opts *options) (vars []*variable, err error) {
 	for i := 0; i < s.NumField(); i++ {
 		field := s.Field(i)
 		fieldType := typeOfSpec.Field(i)
-		if !field.CanSet() || isTrue(fieldType.Tag.Get(TagIgnored)) {
+		if !field.CanSet() && opts.unsafeUnexported && !fieldType.IsExported() && field.CanAddr() {
+			// bypass the read-only flag of unexported fields
+			field = reflect.NewAt(field.Type(), unsafe.Pointer(field.UnsafeAddr())).Elem()
+		}
+		if !field.CanSet() || isTrue(fieldType.Tag.Get(TagIgnored)) || opts.isIgnoredField(fieldType.Name) {
 			continue
 		}
 
+		// raw companions are populated along with the field they refer to
+		if _, ok := fieldType.Tag.Lookup(TagRaw); ok {
+			continue
+		}
+
+		var allocated reflect.Value
 		for field.Kind() == reflect.Ptr {
 			if field.IsNil() {
 				if field.Type().Elem().Kind() != reflect.Struct {
@@ -59,6 +146,9 @@ func gatherInfo(spec any, opts *options) (vars []*variable, err error) {
 				}
 				// nil pointer to struct: create a zero instance
 				field.Set(reflect.New(field.Type().Elem()))
+				if !allocated.IsValid() {
+					allocated = field
+				}
 			}
 			field = field.Elem()
 		}
@@ -66,20 +156,93 @@ func gatherInfo(spec any, opts *options) (vars []*variable, err error) {
 		// Capture information about the config varItem
 		varItem := variable{
 			field:     field,
+			parent:    s,
 			fieldType: fieldType,
 			// Tags:      fieldType.Tag,
 			Opts: opts,
 		}
 
-		varItem.key, varItem.altKey = resolveKey(varItem.Opts.prefix, fieldType)
+		// envprefix tag replaces the inherited prefix for the field and its children
+		prefix := varItem.Opts.prefix
+		if envPrefix, ok := fieldType.Tag.Lookup(TagEnvPrefix); ok {
+			prefix = opts.keyCase(strings.TrimSpace(envPrefix))
+		}
+
+		varItem.key, varItem.altKey = resolveKey(prefix, fieldType, opts)
+		varItem.path = append(opts.fieldPath[:len(opts.fieldPath):len(opts.fieldPath)], fieldType.Name)
+		varItem.lazyPointers = opts.lazyPointers
+		varItem.baseKey = baseKey(varItem.key, opts.rootPrefix, opts.keySeparator)
+		varItem.aliases = resolveAliases(prefix, fieldType, opts)
+
+		if sourcesTag, ok := fieldType.Tag.Lookup(TagSources); ok {
+			varItem.sources, err = parseSources(sourcesTag)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", fieldType.Name, err)
+			}
+		}
+
+		if opts.strictTags && varItem.isRequiredWithDefault() {
+			return nil, fmt.Errorf("field %s: required and default tags are mutually exclusive", fieldType.Name)
+		}
+
+		if name, ok := fieldType.Tag.Lookup(TagLocation); ok {
+			varItem.location, err = time.LoadLocation(name)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", fieldType.Name, err)
+			}
+		}
+
+		// a malformed condition is reported whether or not the field is set
+		if _, ok := fieldType.Tag.Lookup(TagRequiredIf); ok {
+			if _, _, err = varItem.requiredIf(); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidSpecification, err)
+			}
+		}
+
+		if name, ok := fieldType.Tag.Lookup(TagValidate); ok {
+			if err = checkValidation(name); err != nil {
+				return nil, fmt.Errorf("%w: field %s: %v", ErrInvalidSpecification, fieldType.Name, err)
+			}
+		}
+
+		// the value of the referenced field is copied, which reflect forbids for unexported fields
+		if refName, ok := fieldType.Tag.Lookup(TagDefaultFrom); ok {
+			if refField, found := typeOfSpec.FieldByName(refName); found {
+				if ref, refErr := s.FieldByIndexErr(refField.Index); refErr == nil && (!ref.CanInterface() || !ref.CanAddr()) {
+					return nil, fmt.Errorf("%w: default_from of field %s: unexported field %s",
+						ErrInvalidSpecification, fieldType.Name, refName)
+				}
+			}
+		}
+
+		if pipeTag, ok := fieldType.Tag.Lookup(TagPipe); ok {
+			varItem.pipe, err = parsePipe(pipeTag, opts)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", fieldType.Name, err)
+			}
+		}
+
+		nested := field.Kind() == reflect.Struct && isNestedStruct(field)
+		if opts.errorOnUnsupported && !nested && !varItem.isJSON() && !isSupportedType(field.Type()) {
+			return nil, fmt.Errorf("field %s: unsupported type %s", fieldType.Name, field.Type())
+		}
 
 		vars = append(vars, &varItem)
 
 		if field.Kind() == reflect.Struct {
-			// honor Decode if present
-			if decoderFrom(field) == nil && setterFrom(field) == nil && textUnmarshaler(field) == nil && binaryUnmarshaler(field) == nil {
+			// honor Decode if present, json tagged structs are leaves
+			if isNestedStruct(field) && !varItem.isJSON() {
 				innerOpts := opts.copy()
-				if !fieldType.Anonymous {
+				innerOpts.fieldPath = varItem.path
+				if opts.lazyStructPointers && allocated.IsValid() {
+					innerOpts.lazyPointers = append(opts.lazyPointers[:len(opts.lazyPointers):len(opts.lazyPointers)], allocated)
+				}
+				if structPrefix, ok := fieldType.Tag.Lookup(TagPrefix); ok {
+					// prefix tag replaces the name of the field in the keys of its children, empty flattens them
+					innerOpts.prefix = joinPrefix(prefix, opts.keyCase(strings.TrimSpace(structPrefix)), opts.keySeparator)
+				} else if fieldType.Anonymous {
+					innerOpts.prefix = prefix
+				} else {
 					innerOpts.prefix = varItem.key
 				}
 
@@ -96,18 +259,374 @@ func gatherInfo(spec any, opts *options) (vars []*variable, err error) {
 	return vars, nil
 }
 
+// isSupportedType reports whether values of type t can be parsed from a variable.
+func isSupportedType(t reflect.Type) bool {
+	if implementsInterface(t) {
+		return true
+	}
+	switch t {
+	case ipNetType, urlType, bigIntType, bigFloatType:
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128,
+		reflect.Interface:
+		return true
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return isSupportedType(t.Elem())
+	case reflect.Map:
+		return isSupportedType(t.Key()) && isSupportedType(t.Elem())
+	}
+
+	return false
+}
+
+// isNestedStruct reports whether the fields of the struct are configured individually,
+// that is unless the struct decodes itself or is parsed as a whole.
+func isNestedStruct(field reflect.Value) bool {
+	return field.Type() != ipNetType &&
+		registeredDecoder(field.Type()) == nil &&
+		decoderFrom(field) == nil &&
+		setterFrom(field) == nil &&
+		textUnmarshaler(field) == nil &&
+		binaryUnmarshaler(field) == nil
+}
+
+// resolveLazyPointers resets the struct pointers allocated by gatherInfo, see WithLazyStructPointers,
+// whose fields are all unset in the environment, and returns the variables of the others.
+func resolveLazyPointers(vars []*variable) ([]*variable, error) {
+	used := make(map[uintptr]bool)
+	for _, v := range vars {
+		if len(v.lazyPointers) == 0 {
+			continue
+		}
+		_, isLoaded, err := v.envValue()
+		if err != nil {
+			return nil, err
+		}
+		for _, ptr := range v.lazyPointers {
+			used[ptr.UnsafeAddr()] = used[ptr.UnsafeAddr()] || isLoaded
+		}
+	}
+
+	kept := vars[:0]
+	for _, v := range vars {
+		lazy := false
+		for _, ptr := range v.lazyPointers {
+			if !used[ptr.UnsafeAddr()] {
+				ptr.Set(reflect.Zero(ptr.Type()))
+				lazy = true
+			}
+		}
+		if !lazy {
+			kept = append(kept, v)
+		}
+	}
+
+	return kept, nil
+}
+
+// filterVars returns the variables accepted by the key filter of the options.
+func filterVars(vars []*variable, opts *options) []*variable {
+	if opts.keyFilter == nil {
+		return vars
+	}
+
+	filtered := vars[:0]
+	for _, v := range vars {
+		if opts.keyFilter(v.key) {
+			filtered = append(filtered, v)
+		}
+	}
+
+	return filtered
+}
+
+// isRequired tells whether the field must be set. With WithRequiredByDefault fields are required
+// unless they have a default, are tagged optional, required:"false" or required_if.
 func (v *variable) isRequired() bool {
-	return isTrue(v.fieldType.Tag.Get(TagRequired))
+	required, ok := v.fieldType.Tag.Lookup(TagRequired)
+	if ok || !v.Opts.requiredByDefault {
+		return isTrue(required)
+	}
+
+	_, hasDefault := v.fieldType.Tag.Lookup(TagDefault)
+	_, isConditional := v.fieldType.Tag.Lookup(TagRequiredIf)
+
+	return !hasDefault && !isConditional && !isTrue(v.fieldType.Tag.Get(TagOptional))
+}
+
+// isRequiredWithDefault reports whether the field is required but has a default, which makes required meaningless.
+func (v *variable) isRequiredWithDefault() bool {
+	_, hasDefault := v.fieldType.Tag.Lookup(TagDefault)
+	return hasDefault && v.isRequired()
+}
+
+// isRequiredIf evaluates the required_if tag, `Field=value`, against the current value of the sibling field.
+func (v *variable) isRequiredIf() (bool, error) {
+	name, match, err := v.requiredIf()
+	if err != nil {
+		return false, err
+	}
+
+	sibling := v.parent.FieldByName(name)
+	for sibling.Kind() == reflect.Ptr {
+		if sibling.IsNil() {
+			return false, nil
+		}
+		sibling = sibling.Elem()
+	}
+
+	return match(sibling), nil
+}
+
+// requiredIf parses the required_if tag into the name of the sibling field and a comparison of its value
+// with the expected one. The tag is checked against the type of the sibling, whatever its current value.
+func (v *variable) requiredIf() (name string, match func(sibling reflect.Value) bool, err error) {
+	condition := v.fieldType.Tag.Get(TagRequiredIf)
+	name, expected, found := strings.Cut(condition, "=")
+	if !found {
+		return "", nil, fmt.Errorf("invalid required_if %q of field %s: expected Field=value", condition, v.fieldType.Name)
+	}
+	name, expected = strings.TrimSpace(name), strings.TrimSpace(expected)
+
+	siblingType, ok := v.parent.Type().FieldByName(name)
+	if !ok {
+		return "", nil, fmt.Errorf("required_if of field %s: unknown field %s", v.fieldType.Name, name)
+	}
+	typ := siblingType.Type
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	switch typ.Kind() {
+	case reflect.String:
+		return name, func(sibling reflect.Value) bool { return sibling.String() == expected }, nil
+	case reflect.Bool:
+		b, err := strconv.ParseBool(expected)
+		if err != nil {
+			return "", nil, fmt.Errorf("required_if of field %s: %w", v.fieldType.Name, err)
+		}
+		return name, func(sibling reflect.Value) bool { return sibling.Bool() == b }, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(expected, 0, 64)
+		if err != nil {
+			return "", nil, fmt.Errorf("required_if of field %s: %w", v.fieldType.Name, err)
+		}
+		return name, func(sibling reflect.Value) bool { return sibling.Int() == n }, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(expected, 0, 64)
+		if err != nil {
+			return "", nil, fmt.Errorf("required_if of field %s: %w", v.fieldType.Name, err)
+		}
+		return name, func(sibling reflect.Value) bool { return sibling.Uint() == n }, nil
+	}
+
+	return "", nil, fmt.Errorf("required_if of field %s: unsupported type %s of field %s", v.fieldType.Name, siblingType.Type, name)
+}
+
+func (v *variable) isSecret() bool {
+	return isTrue(v.fieldType.Tag.Get(TagSecret))
+}
+
+// isJSON tells whether the value of the field is a JSON document unmarshaled as a whole.
+func (v *variable) isJSON() bool {
+	return isTrue(v.fieldType.Tag.Get(TagJSON))
+}
+
+// oneOf returns the values allowed by the oneof tag, or nil if any value is allowed.
+func (v *variable) oneOf() []string {
+	tag := v.fieldType.Tag.Get(TagOneOf)
+	if strings.TrimSpace(tag) == "" {
+		return nil
+	}
+
+	values := strings.Split(tag, ",")
+	for i := range values {
+		values[i] = strings.TrimSpace(values[i])
+	}
+
+	return values
 }
 
 func (v *variable) value() (value string, isLoaded bool, err error) {
-	envNames := []string{v.key}
+	return v.resolve(true)
+}
 
-	if v.altKey != "" {
-		envNames = append(envNames, v.altKey)
+// resolve resolves the value from every source and applies the field transformers and the pipe tag.
+// The default tag is ignored unless defaultTag is set, e.g. for fields tagged with default_from.
+func (v *variable) resolve(defaultTag bool) (value string, isLoaded bool, err error) {
+	value, isLoaded, err = v.resolveValue(defaultTag)
+	if err != nil || !isLoaded {
+		return
 	}
 
-	for _, envName := range envNames {
+	// field transformers see the trimmed value, before the pipe tag
+	for _, transform := range v.Opts.fieldTransformers {
+		value = transform(v.key, value)
+	}
+
+	if v.pipe != nil {
+		value, err = v.applyPipe(value)
+	}
+
+	return
+}
+
+// resolveValue looks up the value in the environment, then in the defaults file,
+// then in the default tag if defaultTag is set.
+func (v *variable) resolveValue(defaultTag bool) (value string, isLoaded bool, err error) {
+	value, isLoaded, err = v.envValue()
+	if err != nil || isLoaded {
+		return
+	}
+
+	// reset even when a value was found, then dropped as an unset sentinel or an empty value
+	v.origin = SourceUnset
+	value, isLoaded = v.defaultValue(defaultTag)
+	if isLoaded {
+		v.origin = SourceDefault
+		value, err = v.resolveDefault(value)
+	}
+
+	return
+}
+
+// resolveDefault computes a default given by a default function (see RegisterDefaultFunc)
+// and expands variable references in it if enabled by WithDefaultExpansion.
+func (v *variable) resolveDefault(value string) (string, error) {
+	value, err := callDefaultFunc(value)
+	if err != nil {
+		return "", fmt.Errorf("default of field %s: %w", v.fieldType.Name, err)
+	}
+
+	if !v.Opts.expandDefaults {
+		return value, nil
+	}
+
+	return os.Expand(value, func(name string) string {
+		expanded, _ := v.Opts.lookuper.Lookup(name)
+		return expanded
+	}), nil
+}
+
+// defaultValue returns the default from the defaults file or, if defaultTag is set, the default tag.
+func (v *variable) defaultValue(defaultTag bool) (value string, isLoaded bool) {
+	// Load default value from defaults file
+	for _, key := range []string{v.key, v.altKey} {
+		if value, isLoaded = v.Opts.fileDefaults[key]; isLoaded && key != "" {
+			return
+		}
+	}
+
+	if !defaultTag {
+		return "", false
+	}
+
+	// Load default value
+	return v.fieldType.Tag.Lookup(TagDefault)
+}
+
+// envValue resolves the value from the environment or files, ignoring the default tag.
+func (v *variable) envValue() (value string, isLoaded bool, err error) {
+	if v.sources != nil {
+		value, isLoaded, err = v.trySources()
+	} else {
+		value, isLoaded, err = v.tryKeys()
+	}
+	if err != nil {
+		return
+	}
+
+	// Trim space
+	if isLoaded && v.Opts.trimSpaces {
+		value = strings.TrimSpace(value)
+	}
+
+	// Trim cutset
+	if isLoaded && v.Opts.trimCutset != "" {
+		value = strings.Trim(value, v.Opts.trimCutset)
+	}
+
+	// Unset sentinels
+	if isLoaded && v.isUnsetSentinel(value) {
+		value, isLoaded = "", false
+	}
+
+	// Empty values
+	if isLoaded && value == "" && v.isEmptyAsUnset() {
+		isLoaded = false
+	}
+
+	// Unwrap secret
+	if format, ok := v.fieldType.Tag.Lookup(TagSecretFormat); isLoaded && ok {
+		value, err = unwrapSecret(value, format)
+		if err != nil {
+			err = fmt.Errorf("unwrapping %s: %w", v.key, err)
+			return
+		}
+	}
+
+	return
+}
+
+// names returns all environment variable names the variable may be read from.
+func (v *variable) names() []string {
+	if v.sources != nil {
+		var names []string
+		for _, src := range v.sources {
+			if src.kind == SourceEnv {
+				names = append(names, src.locator)
+			}
+		}
+		return names
+	}
+
+	return v.candidateKeys()
+}
+
+// candidateKeys returns the names the value is looked up by, in order of precedence:
+// the key, the fallback key, the alternate key, then the aliases.
+func (v *variable) candidateKeys() []string {
+	candidates := []string{v.key, v.fallbackKey(), v.altKey}
+	candidates = append(candidates, v.aliases...)
+
+	keys := make([]string, 0, len(candidates))
+	seen := make(map[string]struct{}, len(candidates))
+	for _, key := range candidates {
+		if _, found := seen[key]; found || key == "" {
+			continue
+		}
+		seen[key] = struct{}{}
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// checkNameCollisions ensures that no environment variable name is accepted by more than one field.
+func checkNameCollisions(vars []*variable) error {
+	claimed := make(map[string]*variable)
+	for _, v := range vars {
+		for _, name := range v.names() {
+			if other, found := claimed[name]; found && other != v {
+				return fmt.Errorf("environment variable %s is accepted by both %s (%s) and %s (%s)",
+					name, other.fieldType.Name, other.key, v.fieldType.Name, v.key)
+			}
+			claimed[name] = v
+		}
+	}
+
+	return nil
+}
+
+// tryKeys looks up the environment variables named after the field.
+func (v *variable) tryKeys() (value string, isLoaded bool, err error) {
+	for _, envName := range v.candidateKeys() {
 		value, isLoaded, err = v.tryEnv(envName)
 		if err != nil {
 			return
@@ -117,52 +636,197 @@ func (v *variable) value() (value string, isLoaded bool, err error) {
 		}
 	}
 
-	// Trim space
-	if isLoaded && v.Opts.trimSpaces {
-		value = strings.TrimSpace(value)
+	return
+}
+
+// isEmptyAsUnset tells whether an empty value leaves the field unset, see WithEmptyAsUnset.
+func (v *variable) isEmptyAsUnset() bool {
+	if tag, ok := v.fieldType.Tag.Lookup(TagEmptyAsUnset); ok {
+		return isTrue(tag)
 	}
 
-	// Load default value
-	if !isLoaded {
-		value, isLoaded = v.fieldType.Tag.Lookup(TagDefault)
+	return v.Opts.emptyAsUnset
+}
+
+// isUnsetSentinel reports whether the value is one of the configured unset sentinels.
+func (v *variable) isUnsetSentinel(value string) bool {
+	value = strings.TrimSpace(value)
+	for _, sentinel := range v.Opts.unsetSentinels {
+		if value == sentinel {
+			return true
+		}
 	}
 
-	return
+	return false
+}
+
+// setRaw stores the unconverted value into sibling string fields tagged raw with the name of the field.
+func (v *variable) setRaw(value string) {
+	parentType := v.parent.Type()
+	for i := 0; i < parentType.NumField(); i++ {
+		if parentType.Field(i).Tag.Get(TagRaw) != v.fieldType.Name {
+			continue
+		}
+		if raw := v.parent.Field(i); raw.CanSet() && raw.Kind() == reflect.String {
+			raw.SetString(value)
+		}
+	}
+}
+
+// delimiter returns the separator of slice elements and map pairs.
+func (v *variable) delimiter() string {
+	if v.elemDelimiter != "" {
+		return v.elemDelimiter
+	}
+	if delimiter := v.fieldType.Tag.Get(TagDelimiter); delimiter != "" {
+		return delimiter
+	}
+	if v.Opts.delimiter != "" {
+		return v.Opts.delimiter
+	}
+
+	return DefaultDelimiter
+}
+
+// splitElems splits the value of a slice into its elements on the delimiter. With csv slices the value is read
+// as a single CSV record, so elements may be quoted to contain the delimiter, e.g. "a,b",c has two elements.
+func (v *variable) splitElems(value string) ([]string, error) {
+	delimiter := v.delimiter()
+	if !v.Opts.csvSlices {
+		return strings.Split(value, delimiter), nil
+	}
+
+	comma, size := utf8.DecodeRuneInString(delimiter)
+	if size != len(delimiter) {
+		return nil, fmt.Errorf("csv slices require a single character delimiter, got %q", delimiter)
+	}
+
+	reader := csv.NewReader(strings.NewReader(value))
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) != 1 {
+		return nil, fmt.Errorf("expected a single csv record, got %d", len(records))
+	}
+
+	return records[0], nil
+}
+
+// valueDelimiter returns the separator of the elements of slice values of a map: the value_delimiter tag
+// or DefaultValueDelimiter. A value like a:1|2,b:3 is split into pairs on the delimiter first,
+// then each pair on the first map separator, then the slice value on the value delimiter.
+func (v *variable) valueDelimiter() string {
+	if delimiter := v.fieldType.Tag.Get(TagValueDelimiter); delimiter != "" {
+		return delimiter
+	}
+
+	return DefaultValueDelimiter
+}
+
+// timeLayout returns the layout of time.Time values: the time_format tag or the option default.
+// Fields with a location tag default to RFC 3339.
+func (v *variable) timeLayout() string {
+	if layout := v.fieldType.Tag.Get(TagTimeFormat); layout != "" {
+		return layout
+	}
+	if v.Opts.timeLayout == "" && v.location != nil {
+		return time.RFC3339
+	}
+
+	return v.Opts.timeLayout
+}
+
+// mapSeparator returns the separator between keys and values of map pairs.
+func (v *variable) mapSeparator() string {
+	if separator := v.fieldType.Tag.Get(TagMapSeparator); separator != "" {
+		return separator
+	}
+	if v.Opts.mapSeparator != "" {
+		return v.Opts.mapSeparator
+	}
+
+	return DefaultMapSeparator
+}
+
+// isEmptyMapToken reports whether the value is one of the tokens denoting an empty map.
+func (v *variable) isEmptyMapToken(value string) bool {
+	value = strings.TrimSpace(value)
+	for _, token := range v.Opts.emptyMapTokens {
+		if strings.EqualFold(value, strings.TrimSpace(token)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fallbackKey returns the key without the root prefix if prefix fallback is enabled.
+func (v *variable) fallbackKey() string {
+	if !v.Opts.prefixFallback || v.Opts.rootPrefix == "" {
+		return ""
+	}
+
+	if v.baseKey == v.key || v.baseKey == v.altKey {
+		return ""
+	}
+
+	return v.baseKey
 }
 
 func (v *variable) tryEnv(envName string) (value string, isLoaded bool, err error) {
 	// ENV value
-	if value, isLoaded = os.LookupEnv(envName); isLoaded {
+	if value, isLoaded = v.Opts.lookuper.Lookup(envName); isLoaded {
+		v.origin = SourceEnv
 		return
 	}
 
 	// Load from file
-	return v.loadFromFile(envName)
+	value, isLoaded, err = v.loadFromFile(envName)
+	if isLoaded {
+		v.origin = SourceFile
+	}
+	return
 }
 
-func (v *variable) loadFromFile(envName string) (value string, isLoaded bool, err error) {
-	tagValue, needLoad := v.resolveFileLoading()
+// fileKeys returns the names of the variables holding the path of the file to load the value of envName from,
+// in order of precedence, or nil if loading from files is disabled for the field.
+func (v *variable) fileKeys(envName string) []string {
+	suffix, needLoad := v.resolveFileLoading()
 	if !needLoad {
-		return
+		return nil
+	}
+
+	if suffix == "" {
+		suffix = v.Opts.defaultFileSuffix
 	}
 
-	tagValue = strings.TrimSpace(tagValue)
-	if tagValue == "" {
-		tagValue = v.Opts.defaultFileSuffix
+	// Try to acquire file path from env named by `{v.EnvNames}{suffix}`,
+	// the profile specific `{v.EnvNames}{suffix}_{profile}` takes precedence
+	var fileEnvName = v.Opts.keyCase(envName + suffix)
+	if v.Opts.profile != "" {
+		return []string{fileEnvName + "_" + v.Opts.profile, fileEnvName}
 	}
 
-	var filePath string
+	return []string{fileEnvName}
+}
+
+func (v *variable) loadFromFile(envName string) (value string, isLoaded bool, err error) {
+	var rawValue, filePath, fileEnvName string
 	var isFilePathLoaded bool
 
-	// Try to acquire file path from env named by `{v.EnvNames}_{tagValue}`
-	var fileEnvName = strings.ToUpper(envName + tagValue)
-	if filePath, isFilePathLoaded = os.LookupEnv(fileEnvName); isFilePathLoaded {
-		filePath = strings.TrimSpace(filePath)
+	for _, fileEnvName = range v.fileKeys(envName) {
+		if rawValue, isFilePathLoaded = v.Opts.lookuper.Lookup(fileEnvName); isFilePathLoaded {
+			filePath = strings.TrimSpace(rawValue)
 
-		// if envName is set it must contain a file path
-		if filePath == "" {
-			err = fmt.Errorf("environment vairable %s is empty", tagValue)
-			return
+			// if envName is set it must contain a file path
+			if filePath == "" {
+				err = fmt.Errorf("environment vairable %s is empty", fileEnvName)
+				return
+			}
+			break
 		}
 	}
 
@@ -171,76 +835,213 @@ func (v *variable) loadFromFile(envName string) (value string, isLoaded bool, er
 	}
 
 	// try file
-	bytes, err := os.ReadFile(filePath)
+	bytes, err := v.readFile(filePath)
+	if err != nil && v.isFileInline() && isNotPath(err) {
+		// the variable holds the content itself
+		bytes, err = []byte(rawValue), nil
+	}
 	if err != nil {
 		return
 	}
 	value = string(bytes)
 	isLoaded = true
 
+	if v.Opts.requireNonEmptyFiles && strings.TrimSpace(value) == "" && v.isRequired() {
+		err = fmt.Errorf("field %s: file %s pointed by %s is empty", v.fieldType.Name, filePath, fileEnvName)
+	}
+
 	return
 }
 
-func (v *variable) resolveFileLoading() (tagValue string, needLoad bool) {
-	// Loading from file
-	if tagFileValue, tagFileExists := v.fieldType.Tag.Lookup(TagFile); tagFileExists { // if file tag exists
-		// check if it is purely bool
-		tagFileBool, tagFileBoolErr := strconv.ParseBool(tagFileValue)
-		if tagFileBoolErr == nil { // if it's a boolean
-			// enable file loading if it's "true"
-			if tagFileBool {
-				return "", true
-			}
-		} else { // if it contains non-bool value, return the value
-			return tagFileValue, true
+// isFileInline tells whether a *_FILE variable not naming an existing file holds the value itself,
+// see WithInlineFileValues.
+func (v *variable) isFileInline() bool {
+	if tag, ok := v.fieldType.Tag.Lookup(TagFileInline); ok {
+		return isTrue(tag)
+	}
+
+	return v.Opts.inlineFileValues
+}
+
+// isNotPath reports whether reading a file failed because there is no such path,
+// as opposed to an existing file that cannot be read, e.g. for lack of permissions.
+func isNotPath(err error) bool {
+	return errors.Is(err, os.ErrNotExist) ||
+		errors.Is(err, syscall.ENOTDIR) ||
+		errors.Is(err, syscall.ENAMETOOLONG)
+}
+
+// readFile reads the file at path, giving up once the context of the options is done.
+func (v *variable) readFile(path string) ([]byte, error) {
+	ctx := v.Opts.ctx
+	// contexts that are never done, e.g. context.Background, are not worth a goroutine
+	if ctx == nil || ctx.Done() == nil {
+		return os.ReadFile(path)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	type result struct {
+		bytes []byte
+		err   error
+	}
+	// buffered, so the read does not block forever once abandoned
+	done := make(chan result, 1)
+	go func() {
+		bytes, err := os.ReadFile(path)
+		done <- result{bytes: bytes, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.bytes, r.err
+	case <-ctx.Done():
+		return nil, fmt.Errorf("reading %s: %w", path, ctx.Err())
+	}
+}
+
+// resolveFileLoading reports whether the value of the field may be loaded from a file, and the suffix of the
+// variable holding the path of the file. The file tag is either a boolean, enabling or disabling loading with the
+// default suffix regardless of WithoutDefaultLoadingFromFiles, or a suffix like _PATH used instead of the default
+// one for this field only. An empty tag value enables loading with the default suffix.
+func (v *variable) resolveFileLoading() (suffix string, needLoad bool) {
+	// forbidden regardless of the tag
+	if v.Opts.fileLoadingDisabled {
+		return "", false
+	}
+
+	tagFileValue, tagFileExists := v.fieldType.Tag.Lookup(TagFile)
+	if !tagFileExists {
+		return "", v.Opts.isLoadFromFile
+	}
+
+	tagFileValue = strings.TrimSpace(tagFileValue)
+	if tagFileBool, err := strconv.ParseBool(tagFileValue); err == nil {
+		return "", tagFileBool
+	}
+
+	return tagFileValue, true
+}
+
+// unwrapSecret extracts the actual value from a wrapped secret described by format.
+// The only supported format is `json:<field>`, selecting a top level field of a JSON object.
+func unwrapSecret(value, format string) (string, error) {
+	kind, field, _ := strings.Cut(strings.TrimSpace(format), ":")
+	if kind != "json" || field == "" {
+		return "", fmt.Errorf("unsupported secret format %q", format)
+	}
+
+	var wrapper map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(value), &wrapper); err != nil {
+		return "", fmt.Errorf("malformed JSON secret: %w", err)
+	}
+
+	raw, found := wrapper[field]
+	if !found {
+		return "", fmt.Errorf("JSON secret has no field %q", field)
+	}
+
+	// strings are unquoted, anything else is taken verbatim
+	var str string
+	if err := json.Unmarshal(raw, &str); err == nil {
+		return str, nil
+	}
+
+	return string(raw), nil
+}
+
+// resolveAliases returns the keys of the aliases tag, a comma separated list of former names of the field.
+// Each alias is tried with the prefix first, then as is.
+func resolveAliases(prefix string, fieldType reflect.StructField, opts *options) (aliases []string) {
+	tag, ok := fieldType.Tag.Lookup(TagAliases)
+	if !ok {
+		return nil
+	}
+
+	for _, alias := range strings.Split(tag, ",") {
+		alias = opts.keyCase(strings.TrimSpace(alias))
+		if alias == "" {
+			continue
 		}
-	} else { // no file tag
-		// accept defaults
-		if v.Opts.isLoadFromFile {
-			return "", true
+		if prefix != "" {
+			aliases = append(aliases, prefix+opts.keySeparator+alias)
 		}
+		aliases = append(aliases, alias)
 	}
 
-	return "", false
+	return aliases
 }
 
-func resolveKey(prefix string, fieldType reflect.StructField) (key, altKey string) {
-	altKey = strings.TrimSpace(fieldType.Tag.Get(TagEnvconfig))
+// joinPrefix appends name to prefix with the separator, either may be empty.
+func joinPrefix(prefix, name, separator string) string {
+	if prefix == "" || name == "" {
+		return prefix + name
+	}
+
+	return prefix + separator + name
+}
+
+// baseKey returns the key without the root prefix.
+func baseKey(key, rootPrefix, separator string) string {
+	if rootPrefix == "" {
+		return key
+	}
+
+	return strings.TrimPrefix(key, rootPrefix+separator)
+}
+
+func resolveKey(prefix string, fieldType reflect.StructField, opts *options) (key, altKey string) {
+	altKey = strings.TrimSpace(fieldType.Tag.Get(opts.tagName))
 
 	if altKey != "" {
-		altKey = strings.ToUpper(altKey)
+		altKey = opts.keyCase(altKey)
 		key = altKey
 
 	} else {
 		// Best effort to un-pick camel casing as separate words
-		if isTrue(fieldType.Tag.Get(TagSplitWords)) {
-			key = strings.Join(splitWords(fieldType.Name), "_")
+		split := opts.splitWords
+		if tag, ok := fieldType.Tag.Lookup(TagSplitWords); ok {
+			split = isTrue(tag)
+		}
+		if split {
+			key = strings.Join(splitWords(fieldType.Name, opts.joinInitials), "_")
 		} else {
 			key = fieldType.Name
 		}
 	}
 
 	if prefix != "" {
-		key = prefix + "_" + key
+		key = prefix + opts.keySeparator + key
 	}
 
-	key = strings.ToUpper(key)
+	key = opts.keyCase(key)
 
 	return
 }
 
-var gatherRegexp = regexp.MustCompile("([^A-Z]+|[A-Z]+[^A-Z]+|[A-Z]+)")
-var acronymRegexp = regexp.MustCompile("([A-Z]+)([A-Z][^A-Z]+)")
-
-func splitWords(in string) (out []string) {
-	matches := gatherRegexp.FindAllStringSubmatch(in, -1)
-	if len(matches) > 0 {
-		for _, words := range matches {
-			if m := acronymRegexp.FindStringSubmatch(words[0]); len(m) == 3 {
-				out = append(out, m[1], m[2])
-			} else {
-				out = append(out, words[0])
+// splitWords splits a Go identifier into words: before an upper case letter following a lower case letter or a digit,
+// and before the last letter of an acronym followed by a lower case letter, so HTTPSPort is HTTPS Port.
+// Digits stay with the preceding word (S3BucketName is S3 Bucket Name). A single upper case letter is a word
+// of its own (XPos is X Pos) unless joinInitials is set, which keeps it with the following word
+// (OAuth2Token is OAuth2 Token), see WithJoinedInitials. Underscores separate words.
+func splitWords(in string, joinInitials bool) (out []string) {
+	for _, part := range strings.Split(in, "_") {
+		runes := []rune(part)
+		start := 0
+		for i := 1; i < len(runes); i++ {
+			prev, cur := runes[i-1], runes[i]
+			if !unicode.IsUpper(cur) {
+				continue
 			}
+			endOfAcronym := unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1]) && (i-start > 1 || !joinInitials)
+			if !unicode.IsUpper(prev) || endOfAcronym {
+				out = append(out, string(runes[start:i]))
+				start = i
+			}
+		}
+		if start < len(runes) {
+			out = append(out, string(runes[start:]))
 		}
 	}
 
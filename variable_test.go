@@ -1,7 +1,11 @@
 package envconfig
 
 import (
+	"bytes"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -204,3 +208,965 @@ func Test_variable_loadFromFile_enabledByTag(t *testing.T) {
 		})
 	}
 }
+
+func Test_gatherInfo_envPrefix(t *testing.T) {
+	type Tracing struct {
+		Endpoint string
+		Sampler  struct {
+			Rate string
+		}
+	}
+
+	var s struct {
+		Port     string
+		Tracing  Tracing `envprefix:"otel"`
+		Override string  `envprefix:"OTHER"`
+		Embedded struct {
+			Value string
+		}
+	}
+
+	os.Clearenv()
+	os.Setenv("APP_PORT", "8080")
+	os.Setenv("OTEL_TRACING_ENDPOINT", "collector:4317")
+	os.Setenv("OTEL_TRACING_SAMPLER_RATE", "0.5")
+	os.Setenv("APP_TRACING_ENDPOINT", "wrong")
+	os.Setenv("OTHER_OVERRIDE", "other")
+	os.Setenv("APP_EMBEDDED_VALUE", "inherited")
+
+	err := Process(&s, WithPrefix("app"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "8080", s.Port)
+	assert.Equal(t, "collector:4317", s.Tracing.Endpoint)
+	assert.Equal(t, "0.5", s.Tracing.Sampler.Rate)
+	assert.Equal(t, "other", s.Override)
+	assert.Equal(t, "inherited", s.Embedded.Value)
+}
+
+func Test_variable_prefixFallback(t *testing.T) {
+	type spec struct {
+		Port     string
+		Database struct {
+			Host string
+		}
+	}
+
+	tests := []struct {
+		name     string
+		env      map[string]string
+		opts     []Option
+		port     string
+		database string
+	}{
+		{
+			name:     "prefixed only",
+			env:      map[string]string{"APP_PORT": "1", "APP_DATABASE_HOST": "db1"},
+			opts:     []Option{WithPrefix("app"), WithPrefixFallback()},
+			port:     "1",
+			database: "db1",
+		},
+		{
+			name:     "unprefixed only",
+			env:      map[string]string{"PORT": "2", "DATABASE_HOST": "db2"},
+			opts:     []Option{WithPrefix("app"), WithPrefixFallback()},
+			port:     "2",
+			database: "db2",
+		},
+		{
+			name:     "both set",
+			env:      map[string]string{"APP_PORT": "1", "PORT": "2", "APP_DATABASE_HOST": "db1", "DATABASE_HOST": "db2"},
+			opts:     []Option{WithPrefix("app"), WithPrefixFallback()},
+			port:     "1",
+			database: "db1",
+		},
+		{
+			name:     "fallback disabled",
+			env:      map[string]string{"PORT": "2", "DATABASE_HOST": "db2"},
+			opts:     []Option{WithPrefix("app")},
+			port:     "",
+			database: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s spec
+
+			os.Clearenv()
+			for k, v := range tt.env {
+				os.Setenv(k, v)
+			}
+
+			err := Process(&s, tt.opts...)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.port, s.Port)
+			assert.Equal(t, tt.database, s.Database.Host)
+		})
+	}
+}
+
+func Test_variable_secretFormat(t *testing.T) {
+	type spec struct {
+		Token string `secret_format:"json:value"`
+	}
+
+	t.Run("env", func(t *testing.T) {
+		var s spec
+
+		os.Clearenv()
+		os.Setenv("ENV_CONFIG_TOKEN", `{"value":"actual","version":3}`)
+
+		err := Process(&s, WithPrefix("env_config"))
+		assert.NoError(t, err)
+		assert.Equal(t, "actual", s.Token)
+	})
+
+	t.Run("file", func(t *testing.T) {
+		var s spec
+
+		secretFile, err := os.CreateTemp("", "envconfig_test_secret")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(secretFile.Name())
+
+		_, err = secretFile.WriteString("{\"value\": \"from-file\"}\n")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		os.Clearenv()
+		os.Setenv("ENV_CONFIG_TOKEN"+DefaultFileSuffix, secretFile.Name())
+
+		err = Process(&s, WithPrefix("env_config"))
+		assert.NoError(t, err)
+		assert.Equal(t, "from-file", s.Token)
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		var s spec
+
+		os.Clearenv()
+		os.Setenv("ENV_CONFIG_TOKEN", "actual")
+
+		err := Process(&s, WithPrefix("env_config"))
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "ENV_CONFIG_TOKEN")
+			assert.Contains(t, err.Error(), "malformed JSON secret")
+		}
+	})
+
+	t.Run("missing field", func(t *testing.T) {
+		var s spec
+
+		os.Clearenv()
+		os.Setenv("ENV_CONFIG_TOKEN", `{"secret":"actual"}`)
+
+		err := Process(&s, WithPrefix("env_config"))
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), `JSON secret has no field "value"`)
+		}
+	})
+}
+
+func Test_variable_unsetSentinel(t *testing.T) {
+	var s struct {
+		Host      string `default:"localhost"`
+		Port      string `default:"80"`
+		Token     string `required:"true"`
+		Untouched string `default:"untouched"`
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_HOST", " <nil> ")
+	os.Setenv("ENV_CONFIG_PORT", "8080")
+	os.Setenv("ENV_CONFIG_TOKEN", "__UNSET__")
+	os.Setenv("ENV_CONFIG_UNTOUCHED", "<nil>")
+
+	err := Process(&s, WithPrefix("env_config"), WithUnsetSentinel("<nil>", "__UNSET__"))
+	assert.EqualError(t, err, "required key ENV_CONFIG_TOKEN missing value")
+	assert.Equal(t, "localhost", s.Host)
+	assert.Equal(t, "8080", s.Port)
+
+	os.Setenv("ENV_CONFIG_TOKEN", "token")
+	err = Process(&s, WithPrefix("env_config"))
+	assert.NoError(t, err)
+	assert.Equal(t, "<nil>", s.Host)
+	assert.Equal(t, "<nil>", s.Untouched)
+}
+
+func Test_variable_sources(t *testing.T) {
+	type spec struct {
+		Token   string `sources:"env:APP_TOKEN,file:testdata/token.txt,env:TOKEN,literal:fallback"`
+		Missing string `sources:"env:APP_MISSING,file:testdata/missing.txt,env:MISSING,literal:fallback"`
+	}
+
+	tests := []struct {
+		name    string
+		env     map[string]string
+		token   string
+		missing string
+	}{
+		{
+			name:    "first env",
+			env:     map[string]string{"APP_TOKEN": "app", "APP_MISSING": "app"},
+			token:   "app",
+			missing: "app",
+		},
+		{
+			name:    "empty env falls through",
+			env:     map[string]string{"APP_TOKEN": "", "TOKEN": "token", "APP_MISSING": "", "MISSING": "token"},
+			token:   "file",
+			missing: "token",
+		},
+		{
+			name:    "literal",
+			token:   "file",
+			missing: "fallback",
+		},
+		{
+			name:    "field keys are not consulted",
+			env:     map[string]string{"ENV_CONFIG_MISSING": "key"},
+			token:   "file",
+			missing: "fallback",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s spec
+
+			os.Clearenv()
+			for k, v := range tt.env {
+				os.Setenv(k, v)
+			}
+
+			err := Process(&s, WithPrefix("env_config"))
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.token, s.Token)
+			assert.Equal(t, tt.missing, s.Missing)
+		})
+	}
+}
+
+func Test_gatherInfo_malformedSources(t *testing.T) {
+	var s struct {
+		Token string `sources:"env:APP_TOKEN,vault:secret/token"`
+	}
+
+	err := Process(&s)
+	assert.EqualError(t, err, `field Token: invalid sources item "vault:secret/token": unknown kind "vault"`)
+}
+
+func Test_gatherInfo_unsafeUnexported(t *testing.T) {
+	type spec struct {
+		Exported   string
+		unexported string
+		nested     struct {
+			value int
+		}
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_EXPORTED", "exported")
+	os.Setenv("ENV_CONFIG_UNEXPORTED", "unexported")
+	os.Setenv("ENV_CONFIG_NESTED_VALUE", "42")
+
+	var s spec
+	err := Process(&s, WithPrefix("env_config"))
+	assert.NoError(t, err)
+	assert.Equal(t, "exported", s.Exported)
+	assert.Equal(t, "", s.unexported)
+	assert.Equal(t, 0, s.nested.value)
+
+	err = Process(&s, WithPrefix("env_config"), WithUnsafeUnexported())
+	assert.NoError(t, err)
+	assert.Equal(t, "exported", s.Exported)
+	assert.Equal(t, "unexported", s.unexported)
+	assert.Equal(t, 42, s.nested.value)
+}
+
+func Test_checkNameCollisions(t *testing.T) {
+	var collision struct {
+		Host    string
+		Address string `envconfig:"ENV_CONFIG_HOST"`
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_HOST", "localhost")
+
+	err := Process(&collision, WithPrefix("env_config"))
+	assert.NoError(t, err)
+
+	err = Process(&collision, WithPrefix("env_config"), WithStrictTags())
+	assert.EqualError(t, err, "environment variable ENV_CONFIG_HOST is accepted by both Host (ENV_CONFIG_HOST) and Address (ENV_CONFIG_ENV_CONFIG_HOST)")
+
+	var fallback struct {
+		Port       string
+		PublicPort string `sources:"env:PUBLIC_PORT,env:PORT"`
+	}
+
+	err = Process(&fallback, WithPrefix("env_config"), WithPrefixFallback(), WithStrictTags())
+	assert.EqualError(t, err, "environment variable PORT is accepted by both Port (ENV_CONFIG_PORT) and PublicPort (ENV_CONFIG_PUBLICPORT)")
+
+	var clean struct {
+		Host string
+		Port string `envconfig:"PORT"`
+	}
+
+	err = Process(&clean, WithPrefix("env_config"), WithStrictTags())
+	assert.NoError(t, err)
+
+	var requiredWithDefault struct {
+		Host string `required:"true" default:"localhost"`
+		Port string `required:"false" default:"8080"`
+	}
+
+	assert.NoError(t, Process(&requiredWithDefault, WithPrefix("env_config")))
+
+	err = Process(&requiredWithDefault, WithPrefix("env_config"), WithStrictTags())
+	assert.EqualError(t, err, "field Host: required and default tags are mutually exclusive")
+}
+
+func Test_variable_loadFromFile_profile(t *testing.T) {
+	dir := t.TempDir()
+	genericFile := filepath.Join(dir, "generic")
+	prodFile := filepath.Join(dir, "prod")
+	assert.NoError(t, os.WriteFile(genericFile, []byte("generic"), 0o600))
+	assert.NoError(t, os.WriteFile(prodFile, []byte("prod"), 0o600))
+
+	tests := []struct {
+		name     string
+		env      map[string]string
+		opts     []Option
+		expected string
+	}{
+		{
+			name:     "profile specific wins",
+			env:      map[string]string{"DB_PASSWORD_FILE": genericFile, "DB_PASSWORD_FILE_PROD": prodFile},
+			opts:     []Option{WithPrefix("db"), WithDefaultProfile("prod")},
+			expected: "prod",
+		},
+		{
+			name:     "generic without profile specific",
+			env:      map[string]string{"DB_PASSWORD_FILE": genericFile},
+			opts:     []Option{WithPrefix("db"), WithDefaultProfile("prod")},
+			expected: "generic",
+		},
+		{
+			name:     "profile specific ignored for other profile",
+			env:      map[string]string{"DB_PASSWORD_FILE": genericFile, "DB_PASSWORD_FILE_PROD": prodFile},
+			opts:     []Option{WithPrefix("db"), WithDefaultProfile("staging")},
+			expected: "generic",
+		},
+		{
+			name:     "profile specific ignored without profile",
+			env:      map[string]string{"DB_PASSWORD_FILE": genericFile, "DB_PASSWORD_FILE_PROD": prodFile},
+			opts:     []Option{WithPrefix("db")},
+			expected: "generic",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s struct {
+				Password string
+			}
+
+			os.Clearenv()
+			for k, v := range tt.env {
+				os.Setenv(k, v)
+			}
+
+			err := Process(&s, tt.opts...)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, s.Password)
+		})
+	}
+}
+
+func TestWithTagName(t *testing.T) {
+	var s struct {
+		Host    string `env:"SERVICE_HOST" default:"localhost"`
+		Port    int    `envconfig:"SERVICE_PORT" required:"true"`
+		Timeout string `env:"timeout" desc:"request timeout"`
+	}
+
+	os.Clearenv()
+	os.Setenv("APP_SERVICE_HOST", "example.com")
+	os.Setenv("APP_PORT", "8080")
+	os.Setenv("TIMEOUT", "5s")
+
+	if assert.NoError(t, Process(&s, WithPrefix("app"), WithTagName("env"))) {
+		assert.Equal(t, "example.com", s.Host)
+		assert.Equal(t, 8080, s.Port)
+		assert.Equal(t, "5s", s.Timeout)
+	}
+
+	os.Unsetenv("APP_SERVICE_HOST")
+	os.Setenv("APP_SERVICE_PORT", "9090")
+	if assert.NoError(t, Process(&s, WithPrefix("app"))) {
+		assert.Equal(t, "localhost", s.Host)
+		assert.Equal(t, 9090, s.Port)
+	}
+}
+
+func TestStructPrefixTag(t *testing.T) {
+	type Database struct {
+		Host string
+		Port int
+	}
+	var s struct {
+		Primary Database `prefix:"db"`
+		Logging struct {
+			Level string
+		} `prefix:""`
+		Cache Database
+	}
+
+	os.Clearenv()
+	os.Setenv("APP_DB_HOST", "db.local")
+	os.Setenv("APP_DB_PORT", "5432")
+	os.Setenv("APP_LEVEL", "debug")
+	os.Setenv("APP_CACHE_HOST", "cache.local")
+
+	if assert.NoError(t, Process(&s, WithPrefix("app"))) {
+		assert.Equal(t, "db.local", s.Primary.Host)
+		assert.Equal(t, 5432, s.Primary.Port)
+		assert.Equal(t, "debug", s.Logging.Level)
+		assert.Equal(t, "cache.local", s.Cache.Host)
+	}
+
+	var unprefixed struct {
+		Primary Database `prefix:"db"`
+		Logging struct {
+			Level string
+		} `prefix:""`
+	}
+	os.Clearenv()
+	os.Setenv("DB_HOST", "db.local")
+	os.Setenv("LEVEL", "info")
+
+	if assert.NoError(t, Process(&unprefixed)) {
+		assert.Equal(t, "db.local", unprefixed.Primary.Host)
+		assert.Equal(t, "info", unprefixed.Logging.Level)
+	}
+}
+
+func TestAliases(t *testing.T) {
+	type spec struct {
+		Host string `aliases:"server_host,legacy_host"`
+	}
+
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected string
+	}{
+		{
+			name:     "key wins over aliases",
+			env:      map[string]string{"APP_HOST": "current", "APP_SERVER_HOST": "old"},
+			expected: "current",
+		},
+		{
+			name:     "first alias wins over second",
+			env:      map[string]string{"APP_SERVER_HOST": "first", "APP_LEGACY_HOST": "second"},
+			expected: "first",
+		},
+		{
+			name:     "prefixed alias wins over unprefixed",
+			env:      map[string]string{"SERVER_HOST": "bare", "APP_SERVER_HOST": "prefixed"},
+			expected: "prefixed",
+		},
+		{
+			name:     "unprefixed alias",
+			env:      map[string]string{"LEGACY_HOST": "bare"},
+			expected: "bare",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+			for key, value := range tt.env {
+				os.Setenv(key, value)
+			}
+
+			var s spec
+			if assert.NoError(t, Process(&s, WithPrefix("app"))) {
+				assert.Equal(t, tt.expected, s.Host)
+			}
+		})
+	}
+
+	var collision struct {
+		Host       string `aliases:"server"`
+		ServerName string `envconfig:"server"`
+	}
+	err := Process(&collision, WithPrefix("app"), WithStrictTags())
+	assert.EqualError(t, err, "environment variable APP_SERVER is accepted by both Host (APP_HOST) and ServerName (APP_SERVER)")
+}
+
+func TestWithKeySeparator(t *testing.T) {
+	var s struct {
+		LogLevel string `split_words:"true"`
+		DB       struct {
+			Host string
+		}
+	}
+
+	os.Clearenv()
+	os.Setenv("APP__LOG_LEVEL", "debug")
+	os.Setenv("APP__DB__HOST", "db.local")
+
+	if assert.NoError(t, Process(&s, WithPrefix("app"), WithKeySeparator("__"))) {
+		assert.Equal(t, "debug", s.LogLevel)
+		assert.Equal(t, "db.local", s.DB.Host)
+	}
+
+	assert.NoError(t, CheckDisallowed(&s, WithPrefix("app"), WithKeySeparator("__")))
+	os.Setenv("APP__DB_HOST", "db.local")
+	err := CheckDisallowed(&s, WithPrefix("app"), WithKeySeparator("__"))
+	assert.EqualError(t, err, "unknown environment variable APP__DB_HOST")
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, Usagef(&s, buf, "{{range .}}{{usage_key .}}\n{{end}}", WithPrefix("app"), WithKeySeparator("__")))
+	assert.Equal(t, "APP__LOG_LEVEL\nAPP__DB__HOST\n", buf.String())
+}
+
+func TestWithIgnoredFields(t *testing.T) {
+	type Vendor struct {
+		Endpoint string
+		Retries  int
+	}
+	var s struct {
+		Host     string
+		Database struct {
+			User     string
+			Password string
+		}
+		Vendor Vendor
+		Nested struct {
+			Vendor Vendor
+		}
+	}
+
+	os.Clearenv()
+	os.Setenv("APP_HOST", "localhost")
+	os.Setenv("APP_DATABASE_USER", "user")
+	os.Setenv("APP_DATABASE_PASSWORD", "secret")
+	os.Setenv("APP_VENDOR_ENDPOINT", "vendor")
+	os.Setenv("APP_NESTED_VENDOR_RETRIES", "3")
+
+	err := Process(&s, WithPrefix("app"), WithIgnoredFields("Database.Password", "Vendor.*", "Nested"))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "localhost", s.Host)
+		assert.Equal(t, "user", s.Database.User)
+		assert.Equal(t, "", s.Database.Password)
+		assert.Equal(t, "", s.Vendor.Endpoint)
+		assert.Equal(t, 0, s.Nested.Vendor.Retries)
+	}
+
+	var keys []string
+	err = Walk(&s, func(info FieldInfo, _ reflect.Value) error {
+		keys = append(keys, info.Key)
+		return nil
+	}, WithPrefix("app"), WithIgnoredFields("*.Vendor", "Database.*"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"APP_HOST", "APP_VENDOR_ENDPOINT", "APP_VENDOR_RETRIES"}, keys)
+}
+
+func TestWithPreserveKeyCase(t *testing.T) {
+	dir := t.TempDir()
+	passwordFile := filepath.Join(dir, "password")
+	tokenFile := filepath.Join(dir, "token")
+	assert.NoError(t, os.WriteFile(passwordFile, []byte("secret"), 0o600))
+	assert.NoError(t, os.WriteFile(tokenFile, []byte("token"), 0o600))
+
+	type Database struct {
+		Password string
+		Token    string `file:"_path"`
+	}
+
+	var s struct {
+		Name     string
+		Url      string   `envconfig:"serviceUrl"`
+		Database Database `prefix:"db"`
+	}
+
+	os.Clearenv()
+	os.Setenv("app_Name", "api")
+	os.Setenv("APP_NAME", "ignored")
+	os.Setenv("app_serviceUrl", "http://localhost")
+	os.Setenv("app_db_Password_FILE_prod", passwordFile)
+	os.Setenv("app_db_Token_path", tokenFile)
+	os.Setenv("APP_DB_TOKEN_PATH", passwordFile)
+
+	if assert.NoError(t, Process(&s, WithPrefix("app"), WithDefaultProfile("prod"), WithPreserveKeyCase())) {
+		assert.Equal(t, "api", s.Name)
+		assert.Equal(t, "http://localhost", s.Url)
+		assert.Equal(t, "secret", s.Database.Password)
+		assert.Equal(t, "token", s.Database.Token)
+	}
+
+	// upper case by default, the file name suffix included
+	s.Database.Token = ""
+	if assert.NoError(t, Process(&s, WithPrefix("app"))) {
+		assert.Equal(t, "ignored", s.Name)
+		assert.Equal(t, "secret", s.Database.Token)
+	}
+}
+
+func TestWithRequireNonEmptyFiles(t *testing.T) {
+	dir := t.TempDir()
+	emptyFile := filepath.Join(dir, "empty")
+	assert.NoError(t, os.WriteFile(emptyFile, []byte(" \n"), 0o600))
+
+	type spec struct {
+		Password string `required:"true"`
+		Token    string
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_PASSWORD", "secret")
+	os.Setenv("ENV_CONFIG_TOKEN_FILE", emptyFile)
+
+	// optional fields may be empty
+	var s spec
+	assert.NoError(t, Process(&s, WithPrefix("env_config"), WithRequireNonEmptyFiles()))
+
+	os.Unsetenv("ENV_CONFIG_PASSWORD")
+	os.Setenv("ENV_CONFIG_PASSWORD_FILE", emptyFile)
+	assert.NoError(t, Process(&s, WithPrefix("env_config")))
+
+	err := Process(&s, WithPrefix("env_config"), WithRequireNonEmptyFiles())
+	assert.EqualError(t, err, "field Password: file "+emptyFile+" pointed by ENV_CONFIG_PASSWORD_FILE is empty")
+
+	os.Setenv("ENV_CONFIG_PASSWORD_FILE", filepath.Join(dir, "missing"))
+	err = Process(&s, WithPrefix("env_config"), WithRequireNonEmptyFiles())
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestWithFileLoadingDisabled(t *testing.T) {
+	type spec struct {
+		Password string
+		Token    string `file:"true"`
+		Key      string `file:"_PATH"`
+		Cert     string `sources:"file:testdata/token.txt,literal:none"`
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_PASSWORD_FILE", "testdata/token.txt")
+	os.Setenv("ENV_CONFIG_TOKEN_FILE", "testdata/token.txt")
+	os.Setenv("ENV_CONFIG_KEY_PATH", "testdata/token.txt")
+
+	// the file tag still loads without the default loading
+	var s spec
+	if assert.NoError(t, Process(&s, WithPrefix("env_config"), WithoutDefaultLoadingFromFiles())) {
+		assert.Empty(t, s.Password)
+		assert.NotEmpty(t, s.Token)
+		assert.NotEmpty(t, s.Key)
+	}
+
+	s = spec{}
+	if assert.NoError(t, Process(&s, WithPrefix("env_config"), WithFileLoadingDisabled())) {
+		assert.Empty(t, s.Password)
+		assert.Empty(t, s.Token)
+		assert.Empty(t, s.Key)
+		assert.Equal(t, "none", s.Cert)
+	}
+
+	infos, err := Describe(&s, WithPrefix("env_config"), WithFileLoadingDisabled())
+	if assert.NoError(t, err) {
+		for _, info := range infos {
+			assert.False(t, info.LoadsFromFile, info.Name)
+		}
+	}
+}
+
+func TestWithInlineFileValues(t *testing.T) {
+	dir := t.TempDir()
+	pem := "-----BEGIN KEY-----\n" + strings.Repeat("A", 300) + "\n-----END KEY-----\n"
+
+	type spec struct {
+		Password string
+		Key      string
+		Token    string `file_inline:"false"`
+		Cert     string `file_inline:"true"`
+	}
+
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_PASSWORD_FILE", "s3cr3t")
+	os.Setenv("ENV_CONFIG_KEY_FILE", pem)
+	os.Setenv("ENV_CONFIG_TOKEN_FILE", "testdata/token.txt")
+
+	var s spec
+	if assert.NoError(t, Process(&s, WithPrefix("env_config"), WithInlineFileValues())) {
+		assert.Equal(t, "s3cr3t", s.Password)
+		assert.Equal(t, strings.TrimSpace(pem), s.Key)
+		assert.Equal(t, "file", s.Token)
+	}
+
+	// a path to an existing but unreadable file still fails
+	os.Setenv("ENV_CONFIG_PASSWORD_FILE", dir)
+	err := Process(&s, WithPrefix("env_config"), WithInlineFileValues())
+	assert.Error(t, err)
+
+	// inline values are off by default
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_PASSWORD_FILE", "s3cr3t")
+	err = Process(&s, WithPrefix("env_config"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	// unless tagged
+	s = spec{}
+	os.Clearenv()
+	os.Setenv("ENV_CONFIG_CERT_FILE", "cert")
+	os.Setenv("ENV_CONFIG_TOKEN_FILE", "token")
+	err = Process(&s, WithPrefix("env_config"), WithInlineFileValues())
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	os.Unsetenv("ENV_CONFIG_TOKEN_FILE")
+	if assert.NoError(t, Process(&s, WithPrefix("env_config"))) {
+		assert.Equal(t, "cert", s.Cert)
+	}
+}
+
+type AppConfig struct {
+	LogLevel string `split_words:"true"`
+	Database struct {
+		Host string
+	}
+}
+
+type HTTPServerConfig struct {
+	Port int
+}
+
+func TestWithPrefixFromType(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("APPCONFIG_LOG_LEVEL", "debug")
+	os.Setenv("APP_CONFIG_LOG_LEVEL", "info")
+	os.Setenv("APP_LOG_LEVEL", "warn")
+	os.Setenv("APP_DATABASE_HOST", "db")
+	os.Setenv("HTTP_SERVER_PORT", "8080")
+	os.Setenv("OTHER_PORT", "9090")
+
+	tests := []struct {
+		name      string
+		transform TypeNameTransform
+		expected  string
+	}{
+		{name: "as is", expected: "debug"},
+		{name: "split words", transform: TypeNameSplitWords, expected: "info"},
+		{name: "trim config", transform: TypeNameSplitWords | TypeNameTrimConfig, expected: "warn"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s AppConfig
+			if assert.NoError(t, Process(&s, WithPrefixFromType(tt.transform))) {
+				assert.Equal(t, tt.expected, s.LogLevel)
+			}
+		})
+	}
+
+	// each specification gets its own prefix, nested structs are prefixed as usual
+	var app AppConfig
+	var server HTTPServerConfig
+	err := ProcessMany([]any{&app, &server}, WithPrefixFromType(TypeNameSplitWords|TypeNameTrimConfig))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "db", app.Database.Host)
+		assert.Equal(t, 8080, server.Port)
+	}
+
+	// the last prefix option applies
+	assert.NoError(t, Process(&server, WithPrefixFromType(TypeNameTrimConfig), WithPrefix("other")))
+	assert.Equal(t, 9090, server.Port)
+	assert.NoError(t, Process(&server, WithPrefix("other"), WithPrefixFromType(TypeNameSplitWords|TypeNameTrimConfig)))
+	assert.Equal(t, 8080, server.Port)
+
+	infos, err := Describe(&server, WithPrefixFromType(TypeNameSplitWords|TypeNameTrimConfig))
+	if assert.NoError(t, err) && assert.Len(t, infos, 1) {
+		assert.Equal(t, "HTTP_SERVER_PORT", infos[0].Key)
+		assert.Equal(t, "PORT", infos[0].BaseKey)
+	}
+
+	// the sub-prefix of ProcessPrefix is joined to the prefix of the type
+	app = AppConfig{}
+	err = ProcessPrefix(&app, "database", WithPrefixFromType(TypeNameSplitWords|TypeNameTrimConfig))
+	if assert.NoError(t, err) {
+		assert.Equal(t, "db", app.Database.Host)
+		assert.Empty(t, app.LogLevel)
+	}
+}
+
+func TestWithSplitWords(t *testing.T) {
+	type DatabaseConn struct {
+		MaxOpen int
+	}
+
+	var s struct {
+		LogLevel     string
+		APIKey       string
+		BindAddr     string `split_words:"false"`
+		ServiceURL   string `envconfig:"SERVICE_ENDPOINT"`
+		DatabaseConn DatabaseConn
+	}
+
+	os.Clearenv()
+	os.Setenv("APP_LOG_LEVEL", "debug")
+	os.Setenv("APP_API_KEY", "key")
+	os.Setenv("APP_BINDADDR", ":80")
+	os.Setenv("APP_SERVICE_ENDPOINT", "http://svc")
+	os.Setenv("APP_DATABASE_CONN_MAX_OPEN", "5")
+
+	if assert.NoError(t, Process(&s, WithPrefix("app"), WithSplitWords())) {
+		assert.Equal(t, "debug", s.LogLevel)
+		assert.Equal(t, "key", s.APIKey)
+		assert.Equal(t, ":80", s.BindAddr)
+		assert.Equal(t, "http://svc", s.ServiceURL)
+		assert.Equal(t, 5, s.DatabaseConn.MaxOpen)
+	}
+
+	infos, err := Describe(&s, WithPrefix("app"))
+	if assert.NoError(t, err) && assert.Len(t, infos, 5) {
+		assert.Equal(t, "APP_LOGLEVEL", infos[0].Key)
+		assert.Equal(t, "APP_DATABASECONN_MAXOPEN", infos[4].Key)
+	}
+
+	var initials struct {
+		OAuthToken string
+		XPos       int
+	}
+	infos, err = Describe(&initials, WithSplitWords())
+	if assert.NoError(t, err) && assert.Len(t, infos, 2) {
+		assert.Equal(t, "O_AUTH_TOKEN", infos[0].Key)
+		assert.Equal(t, "X_POS", infos[1].Key)
+	}
+	infos, err = Describe(&initials, WithSplitWords(), WithJoinedInitials())
+	if assert.NoError(t, err) && assert.Len(t, infos, 2) {
+		assert.Equal(t, "OAUTH_TOKEN", infos[0].Key)
+		assert.Equal(t, "XPOS", infos[1].Key)
+	}
+}
+
+func Test_splitWords(t *testing.T) {
+	tests := []struct {
+		in           string
+		joinInitials bool
+		expected     string
+	}{
+		{in: "Name", expected: "NAME"},
+		{in: "LogLevel", expected: "LOG_LEVEL"},
+		{in: "lowerCamel", expected: "LOWER_CAMEL"},
+		// acronyms
+		{in: "ID", expected: "ID"},
+		{in: "UserID", expected: "USER_ID"},
+		{in: "APIKey", expected: "API_KEY"},
+		{in: "HTTPSPort", expected: "HTTPS_PORT"},
+		{in: "MultiWordACRWithAutoSplit", expected: "MULTI_WORD_ACR_WITH_AUTO_SPLIT"},
+		{in: "ServiceURL", expected: "SERVICE_URL"},
+		{in: "PointA", expected: "POINT_A"},
+		// single upper case letters
+		{in: "XPos", expected: "X_POS"},
+		{in: "ATest", expected: "A_TEST"},
+		{in: "OAuth", expected: "O_AUTH"},
+		{in: "XPos", joinInitials: true, expected: "XPOS"},
+		{in: "OAuth", joinInitials: true, expected: "OAUTH"},
+		{in: "HTTPSPort", joinInitials: true, expected: "HTTPS_PORT"},
+		// digits
+		{in: "OAuth2Token", expected: "O_AUTH2_TOKEN"},
+		{in: "OAuth2Token", joinInitials: true, expected: "OAUTH2_TOKEN"},
+		{in: "S3BucketName", expected: "S3_BUCKET_NAME"},
+		{in: "HTTP2Server", expected: "HTTP2_SERVER"},
+		{in: "X509Cert", expected: "X509_CERT"},
+		{in: "Md5Sum", expected: "MD5_SUM"},
+		{in: "V2API", expected: "V2_API"},
+		{in: "Retries3", expected: "RETRIES3"},
+		// underscores
+		{in: "Log_Level", expected: "LOG_LEVEL"},
+		{in: "DB__Host_", expected: "DB_HOST"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			assert.Equal(t, tt.expected, strings.ToUpper(strings.Join(splitWords(tt.in, tt.joinInitials), "_")))
+		})
+	}
+}
+
+func TestFileTagSuffix(t *testing.T) {
+	type spec struct {
+		Default  string
+		Enabled  string `file:"true"`
+		Disabled string `file:"false"`
+		Empty    string `file:""`
+		Padded   string `file:" true "`
+		Suffix   string `file:"_PATH"`
+	}
+
+	tests := []struct {
+		name     string
+		opts     []Option
+		env      map[string]string
+		expected spec
+	}{
+		{
+			name: "default suffix",
+			env: map[string]string{
+				"ENV_CONFIG_DEFAULT_FILE":  "testdata/token.txt",
+				"ENV_CONFIG_ENABLED_FILE":  "testdata/token.txt",
+				"ENV_CONFIG_DISABLED_FILE": "testdata/token.txt",
+				"ENV_CONFIG_EMPTY_FILE":    "testdata/token.txt",
+				"ENV_CONFIG_PADDED_FILE":   "testdata/token.txt",
+				"ENV_CONFIG_SUFFIX_FILE":   "testdata/token.txt",
+			},
+			expected: spec{Default: "file", Enabled: "file", Empty: "file", Padded: "file"},
+		},
+		{
+			name: "tag suffix",
+			env: map[string]string{
+				"ENV_CONFIG_DEFAULT_PATH": "testdata/token.txt",
+				"ENV_CONFIG_ENABLED_PATH": "testdata/token.txt",
+				"ENV_CONFIG_SUFFIX_PATH":  "testdata/token.txt",
+			},
+			expected: spec{Suffix: "file"},
+		},
+		{
+			name: "global suffix",
+			opts: []Option{WithDefaultFileSuffix("_SECRET")},
+			env: map[string]string{
+				"ENV_CONFIG_DEFAULT_SECRET": "testdata/token.txt",
+				"ENV_CONFIG_ENABLED_SECRET": "testdata/token.txt",
+				"ENV_CONFIG_SUFFIX_SECRET":  "testdata/token.txt",
+				"ENV_CONFIG_SUFFIX_PATH":    "testdata/token.txt",
+			},
+			expected: spec{Default: "file", Enabled: "file", Suffix: "file"},
+		},
+		{
+			name: "without default loading",
+			opts: []Option{WithoutDefaultLoadingFromFiles()},
+			env: map[string]string{
+				"ENV_CONFIG_DEFAULT_FILE": "testdata/token.txt",
+				"ENV_CONFIG_ENABLED_FILE": "testdata/token.txt",
+				"ENV_CONFIG_EMPTY_FILE":   "testdata/token.txt",
+				"ENV_CONFIG_SUFFIX_PATH":  "testdata/token.txt",
+			},
+			expected: spec{Enabled: "file", Empty: "file", Suffix: "file"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+			for key, value := range tt.env {
+				os.Setenv(key, value)
+			}
+
+			var s spec
+			opts := append([]Option{WithPrefix("env_config")}, tt.opts...)
+			if assert.NoError(t, Process(&s, opts...)) {
+				assert.Equal(t, tt.expected, s)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package envconfig
+
+import "reflect"
+
+// FieldInfo describes a configuration field of a specification.
+type FieldInfo struct {
+	// Name is the name of the struct field.
+	Name string
+	// Key is the environment variable name the field is read from.
+	Key string
+	// BaseKey is Key without the prefix set by WithPrefix.
+	BaseKey string
+	// AltKey is the alternate name from the envconfig tag, if any.
+	AltKey      string
+	Tag         reflect.StructTag
+	Required    bool
+	Default     string
+	Description string
+	Secret      bool
+}
+
+// VarInfo describes a configuration variable read by Process.
+type VarInfo struct {
+	FieldInfo
+	// TypeName is the Go type of the field.
+	TypeName string
+	// LoadsFromFile tells whether the value may be loaded from a file pointed by a *_FILE variable.
+	LoadsFromFile bool
+}
+
+// Describe returns the variables Process reads for the specification with the same options, in declaration order.
+func Describe(spec any, optsValues ...Option) ([]VarInfo, error) {
+	opts := defaultOptions().apply(optsValues...).forSpec(spec)
+
+	vars, err := gatherInfo(spec, opts)
+	if err != nil {
+		return nil, err
+	}
+	vars = filterVars(vars, opts)
+
+	infos := make([]VarInfo, 0, len(vars))
+	for _, v := range vars {
+		_, loadsFromFile := v.resolveFileLoading()
+		infos = append(infos, VarInfo{
+			FieldInfo:     v.info(),
+			TypeName:      v.field.Type().String(),
+			LoadsFromFile: loadsFromFile,
+		})
+	}
+
+	return infos, nil
+}
+
+// Walk calls fn for each leaf field of the specification in declaration order,
+// passing the field metadata and its settable value. An error returned by fn aborts the walk.
+// Fields left out by WithKeyFilter are skipped, as with Describe.
+func Walk(spec any, fn func(FieldInfo, reflect.Value) error, optsValues ...Option) error {
+	opts := defaultOptions().apply(optsValues...).forSpec(spec)
+
+	vars, err := gatherInfo(spec, opts)
+	if err != nil {
+		return err
+	}
+	vars = filterVars(vars, opts)
+
+	for _, v := range vars {
+		if err = fn(v.info(), v.field); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (v *variable) info() FieldInfo {
+	return FieldInfo{
+		Name:        v.fieldType.Name,
+		Key:         v.key,
+		BaseKey:     v.baseKey,
+		AltKey:      v.altKey,
+		Tag:         v.fieldType.Tag,
+		Required:    v.isRequired(),
+		Default:     v.fieldType.Tag.Get(TagDefault),
+		Description: v.fieldType.Tag.Get("desc"),
+		Secret:      v.isSecret(),
+	}
+}
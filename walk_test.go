@@ -0,0 +1,117 @@
+package envconfig
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWalk(t *testing.T) {
+	var s struct {
+		Port     int    `required:"true" desc:"listen port"`
+		Host     string `default:"localhost"`
+		Ignored  string `ignored:"true"`
+		Database struct {
+			Password string `envconfig:"db_password" secret:"true"`
+		}
+	}
+
+	var infos []FieldInfo
+	err := Walk(&s, func(info FieldInfo, value reflect.Value) error {
+		infos = append(infos, info)
+		if value.Kind() == reflect.String {
+			value.SetString("visited")
+		}
+		return nil
+	}, WithPrefix("app"))
+
+	assert.NoError(t, err)
+	if assert.Len(t, infos, 3) {
+		assert.Equal(t, "Port", infos[0].Name)
+		assert.Equal(t, "APP_PORT", infos[0].Key)
+		assert.True(t, infos[0].Required)
+		assert.Equal(t, "listen port", infos[0].Description)
+
+		assert.Equal(t, "APP_HOST", infos[1].Key)
+		assert.Equal(t, "localhost", infos[1].Default)
+
+		assert.Equal(t, "Password", infos[2].Name)
+		assert.Equal(t, "APP_DATABASE_DB_PASSWORD", infos[2].Key)
+		assert.Equal(t, "DB_PASSWORD", infos[2].AltKey)
+		assert.True(t, infos[2].Secret)
+	}
+
+	assert.Equal(t, "visited", s.Host)
+	assert.Equal(t, "visited", s.Database.Password)
+	assert.Equal(t, "", s.Ignored)
+
+	var keys []string
+	err = Walk(&s, func(info FieldInfo, value reflect.Value) error {
+		keys = append(keys, info.Key)
+		return nil
+	}, WithPrefix("app"), WithKeyFilter(func(key string) bool { return key != "APP_HOST" }))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"APP_PORT", "APP_DATABASE_DB_PASSWORD"}, keys)
+}
+
+func TestWalkAbort(t *testing.T) {
+	var s struct {
+		First  string
+		Second string
+	}
+
+	abortErr := errors.New("abort")
+	visited := 0
+	err := Walk(&s, func(info FieldInfo, value reflect.Value) error {
+		visited++
+		return abortErr
+	})
+
+	assert.Same(t, abortErr, err)
+	assert.Equal(t, 1, visited)
+}
+
+func TestDescribe(t *testing.T) {
+	var s struct {
+		Port     int    `required:"true" desc:"listen port"`
+		Host     string `default:"localhost"`
+		Ignored  string `ignored:"true"`
+		Database struct {
+			Password string        `envconfig:"db_password" secret:"true"`
+			Timeout  time.Duration `file:"false"`
+		}
+	}
+
+	infos, err := Describe(&s, WithPrefix("app"))
+	if !assert.NoError(t, err) || !assert.Len(t, infos, 4) {
+		return
+	}
+
+	assert.Equal(t, "APP_PORT", infos[0].Key)
+	assert.Equal(t, "int", infos[0].TypeName)
+	assert.True(t, infos[0].Required)
+	assert.Equal(t, "listen port", infos[0].Description)
+	assert.True(t, infos[0].LoadsFromFile)
+
+	assert.Equal(t, "APP_HOST", infos[1].Key)
+	assert.Equal(t, "localhost", infos[1].Default)
+
+	assert.Equal(t, "APP_DATABASE_DB_PASSWORD", infos[2].Key)
+	assert.Equal(t, "DB_PASSWORD", infos[2].AltKey)
+	assert.True(t, infos[2].Secret)
+
+	assert.Equal(t, "APP_DATABASE_TIMEOUT", infos[3].Key)
+	assert.Equal(t, "time.Duration", infos[3].TypeName)
+	assert.False(t, infos[3].LoadsFromFile)
+
+	infos, err = Describe(&s, WithPrefix("app"), WithoutDefaultLoadingFromFiles(), WithKeyFilter(func(key string) bool {
+		return key == "APP_PORT"
+	}))
+	if assert.NoError(t, err) && assert.Len(t, infos, 1) {
+		assert.Equal(t, "APP_PORT", infos[0].Key)
+		assert.False(t, infos[0].LoadsFromFile)
+	}
+}